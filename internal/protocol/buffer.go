@@ -58,3 +58,171 @@ func (cb *CommandBuffer) WriteEof() {
 func Color(r, g, b, a uint8) uint32 {
 	return (uint32(a) << 24) | (uint32(b) << 16) | (uint32(g) << 8) | uint32(r)
 }
+
+// WriteSetMatrix: [a, b, c, d, tx, ty], see Matrix
+func (cb *CommandBuffer) WriteSetMatrix(m Matrix) {
+	cb.writeHeader(OpSetMatrix, 6)
+	for _, v := range m {
+		cb.WriteFloat(v)
+	}
+}
+
+// WriteSetFill: [color]
+func (cb *CommandBuffer) WriteSetFill(color uint32) {
+	cb.writeHeader(OpSetFill, 1)
+	cb.WriteUint(color)
+}
+
+// WriteSetStroke: [color, width]
+func (cb *CommandBuffer) WriteSetStroke(color uint32, width float32) {
+	cb.writeHeader(OpSetStroke, 2)
+	cb.WriteUint(color)
+	cb.WriteFloat(width)
+}
+
+// WriteDrawRect: [x, y, w, h, mode], fills then strokes with the current
+// state depending on mode
+func (cb *CommandBuffer) WriteDrawRect(x, y, w, h float32, mode DrawMode) {
+	cb.writeHeader(OpDrawRect, 5)
+	cb.WriteFloat(x)
+	cb.WriteFloat(y)
+	cb.WriteFloat(w)
+	cb.WriteFloat(h)
+	cb.WriteUint(uint32(mode))
+}
+
+// WriteDrawLine: [x0, y0, x1, y1], stroked with the current state
+func (cb *CommandBuffer) WriteDrawLine(x0, y0, x1, y1 float32) {
+	cb.writeHeader(OpDrawLine, 4)
+	cb.WriteFloat(x0)
+	cb.WriteFloat(y0)
+	cb.WriteFloat(x1)
+	cb.WriteFloat(y1)
+}
+
+// WriteDrawText: [x, y, string], filled with the current state from the
+// baseline start at (x, y). See writeString.
+func (cb *CommandBuffer) WriteDrawText(x, y float32, s string) {
+	cb.writeHeader(OpDrawText, uint32(2+stringWords(s)))
+	cb.WriteFloat(x)
+	cb.WriteFloat(y)
+	cb.writeString(s)
+}
+
+// WriteSetFont: [size, letterSpacing, family string], see writeString
+func (cb *CommandBuffer) WriteSetFont(size, letterSpacing float32, family string) {
+	cb.writeHeader(OpSetFont, uint32(2+stringWords(family)))
+	cb.WriteFloat(size)
+	cb.WriteFloat(letterSpacing)
+	cb.writeString(family)
+}
+
+// stringWords is the size of s written by writeString
+func stringWords(s string) int {
+	return 1 + (len(s)+3)/4
+}
+
+// writeString: [byteLength, utf-8 bytes...], the bytes packed
+// little-endian four to a word
+func (cb *CommandBuffer) writeString(s string) {
+	cb.WriteUint(uint32(len(s)))
+	for i := 0; i < len(s); i += 4 {
+		var w uint32
+		for j := 0; j < 4 && i+j < len(s); j++ {
+			w |= uint32(s[i+j]) << (8 * j)
+		}
+		cb.WriteUint(w)
+	}
+}
+
+// WritePushGroup: [opacity], saves the state; what is drawn until the
+// matching PopGroup is composited with opacity
+func (cb *CommandBuffer) WritePushGroup(opacity float32) {
+	cb.writeHeader(OpPushGroup, 1)
+	cb.WriteFloat(opacity)
+}
+
+// WritePopGroup: [], restores the state of the matching PushGroup
+func (cb *CommandBuffer) WritePopGroup() {
+	cb.writeHeader(OpPopGroup, 0)
+}
+
+// WriteClipRect: [x, y, w, h] in the current matrix, intersected with
+// the clip of the group
+func (cb *CommandBuffer) WriteClipRect(x, y, w, h float32) {
+	cb.writeHeader(OpClipRect, 4)
+	cb.WriteFloat(x)
+	cb.WriteFloat(y)
+	cb.WriteFloat(w)
+	cb.WriteFloat(h)
+}
+
+// WriteDrawRRect: [x, y, w, h, top-left, top-right, bottom-right,
+// bottom-left, mode]
+func (cb *CommandBuffer) WriteDrawRRect(x, y, w, h float32, radii [4]float32, mode DrawMode) {
+	cb.writeHeader(OpDrawRRect, 9)
+	cb.WriteFloat(x)
+	cb.WriteFloat(y)
+	cb.WriteFloat(w)
+	cb.WriteFloat(h)
+	for _, r := range radii {
+		cb.WriteFloat(r)
+	}
+	cb.WriteUint(uint32(mode))
+}
+
+// WriteDrawOval: [x, y, w, h, mode], the ellipse inside the box
+func (cb *CommandBuffer) WriteDrawOval(x, y, w, h float32, mode DrawMode) {
+	cb.writeHeader(OpDrawOval, 5)
+	cb.WriteFloat(x)
+	cb.WriteFloat(y)
+	cb.WriteFloat(w)
+	cb.WriteFloat(h)
+	cb.WriteUint(uint32(mode))
+}
+
+// WritePathBegin: [], starts an empty path
+func (cb *CommandBuffer) WritePathBegin() {
+	cb.writeHeader(OpPathBegin, 0)
+}
+
+// WritePathMove: [x, y]
+func (cb *CommandBuffer) WritePathMove(x, y float32) {
+	cb.writeHeader(OpPathMove, 2)
+	cb.WriteFloat(x)
+	cb.WriteFloat(y)
+}
+
+// WritePathLine: [x, y]
+func (cb *CommandBuffer) WritePathLine(x, y float32) {
+	cb.writeHeader(OpPathLine, 2)
+	cb.WriteFloat(x)
+	cb.WriteFloat(y)
+}
+
+// WritePathClose: []
+func (cb *CommandBuffer) WritePathClose() {
+	cb.writeHeader(OpPathClose, 0)
+}
+
+// WritePathFill: [], fills the path even-odd with the current state
+func (cb *CommandBuffer) WritePathFill() {
+	cb.writeHeader(OpPathFill, 0)
+}
+
+// WritePathStroke: [], strokes the path with the current state
+func (cb *CommandBuffer) WritePathStroke() {
+	cb.writeHeader(OpPathStroke, 0)
+}
+
+// WriteDrawImage: [texture, x, y, w, h, scaleMode], texture is the
+// ImageProps.TextureID JS loaded
+func (cb *CommandBuffer) WriteDrawImage(texture uint32, x, y, w, h float32, scaleMode uint32) {
+	cb.writeHeader(OpDrawImg, 6)
+	cb.WriteUint(texture)
+	cb.WriteFloat(x)
+	cb.WriteFloat(y)
+	cb.WriteFloat(w)
+	cb.WriteFloat(h)
+	cb.WriteUint(scaleMode)
+}
@@ -0,0 +1,138 @@
+package protocol
+
+import (
+	"math"
+	"unsafe"
+)
+
+// InputVersion changes whenever the layout below changes,
+// JS must refuse to write into a layout it doesn't know
+const InputVersion = 1
+
+// Layout of the input state, in uint32 words. JS writes events as they
+// come, Go reads the whole state once per Update instead of one WASM call
+// per event. Floats are float32 bits.
+const (
+	InputVersionWord = 0 // Go writes InputVersion
+	InputSizeWord    = 1 // Go writes InputWords
+	InputPointerX    = 2 // CSS pixel, relative to the canvas
+	InputPointerY    = 3
+	InputButtons     = 4 // Bitmask of pressed buttons, like PointerEvent.buttons
+	InputModifiers   = 5 // Bitmask of Mod*
+	InputWheelX      = 6 // JS adds every wheel delta, Go resets to 0 after reading
+	InputWheelY      = 7
+	// Both key counters wrap around past MaxUint32, write - read in
+	// uint32 arithmetic stays the number of pending events
+	InputKeyWrite = 8 // Key events JS wrote since the start
+	InputKeyRead  = 9 // Key events Go read, written by Go only
+	InputKeyRing  = 10
+
+	// Key ring entries: [key code, action | modifiers << 8]
+	InputKeyWords = 2
+	// Power of two so JS indexes with (count & (InputKeySlots - 1))
+	InputKeySlots = 32
+
+	InputWords = InputKeyRing + InputKeySlots*InputKeyWords
+)
+
+// Modifier bits
+const (
+	ModShift uint32 = 1 << iota
+	ModCtrl
+	ModAlt
+	ModMeta
+)
+
+// Pointer buttons, same bits as PointerEvent.buttons
+const (
+	ButtonLeft uint32 = 1 << iota
+	ButtonRight
+	ButtonMiddle
+)
+
+// Key actions
+const (
+	KeyDown uint32 = iota
+	KeyUp
+)
+
+// Key codes, same as KeyboardEvent.keyCode
+const (
+	KeySpace uint32 = 32
+)
+
+type KeyEvent struct {
+	Code      uint32
+	Action    uint32
+	Modifiers uint32
+}
+
+// Input is what happened since the previous Read
+type Input struct {
+	X, Y      float32
+	Buttons   uint32
+	Modifiers uint32
+	// Buttons that went down or up since the previous Read
+	Pressed, Released uint32
+	WheelX, WheelY    float32
+	// In the order they were written. Events JS wrote while the ring was
+	// full are lost, only the latest InputKeySlots are kept.
+	Keys []KeyEvent
+}
+
+// InputState is the memory JS writes input into
+type InputState struct {
+	words [InputWords]uint32
+}
+
+func NewInputState() *InputState {
+	s := &InputState{}
+	s.words[InputVersionWord] = InputVersion
+	s.words[InputSizeWord] = InputWords
+	return s
+}
+
+func (s *InputState) GetPtr() unsafe.Pointer {
+	return unsafe.Pointer(&s.words[0])
+}
+
+// Pointer returns the latest pointer position, for events JS sends
+// between two Updates
+func (s *InputState) Pointer() (float32, float32) {
+	return math.Float32frombits(s.words[InputPointerX]), math.Float32frombits(s.words[InputPointerY])
+}
+
+// Read fills in with the current state and consumes the wheel deltas and
+// key events. in keeps the buttons of the previous Read to tell edges.
+func (s *InputState) Read(in *Input) {
+	w := &s.words
+
+	buttons := w[InputButtons]
+	in.Pressed = buttons &^ in.Buttons
+	in.Released = in.Buttons &^ buttons
+	in.Buttons = buttons
+
+	in.X = math.Float32frombits(w[InputPointerX])
+	in.Y = math.Float32frombits(w[InputPointerY])
+	in.Modifiers = w[InputModifiers]
+
+	in.WheelX = math.Float32frombits(w[InputWheelX])
+	in.WheelY = math.Float32frombits(w[InputWheelY])
+	w[InputWheelX], w[InputWheelY] = 0, 0
+
+	in.Keys = in.Keys[:0]
+	write, read := w[InputKeyWrite], w[InputKeyRead]
+	if write-read > InputKeySlots {
+		// Overwritten by JS before we got to them
+		read = write - InputKeySlots
+	}
+	for ; read != write; read++ {
+		i := InputKeyRing + int(read&(InputKeySlots-1))*InputKeyWords
+		in.Keys = append(in.Keys, KeyEvent{
+			Code:      w[i],
+			Action:    w[i+1] & 0xFF,
+			Modifiers: w[i+1] >> 8,
+		})
+	}
+	w[InputKeyRead] = write
+}
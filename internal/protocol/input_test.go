@@ -0,0 +1,84 @@
+package protocol
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+)
+
+// jsView is the state as JS sees it through the pointer
+func jsView(s *InputState) []uint32 {
+	return unsafe.Slice((*uint32)(s.GetPtr()), InputWords)
+}
+
+// pressKey writes a key event the way the JS glue does
+func pressKey(mem []uint32, code, action, modifiers uint32) {
+	i := InputKeyRing + int(mem[InputKeyWrite]&(InputKeySlots-1))*InputKeyWords
+	mem[i], mem[i+1] = code, action|modifiers<<8
+	mem[InputKeyWrite]++
+}
+
+func TestInputState_Layout(t *testing.T) {
+	mem := jsView(NewInputState())
+	if mem[InputVersionWord] != InputVersion || mem[InputSizeWord] != InputWords {
+		t.Errorf("Header should describe the layout, got %v %v", mem[InputVersionWord], mem[InputSizeWord])
+	}
+}
+
+func TestInputState_Read(t *testing.T) {
+	s := NewInputState()
+	mem := jsView(s)
+	var in Input
+
+	mem[InputPointerX] = math.Float32bits(12.5)
+	mem[InputPointerY] = math.Float32bits(40)
+	mem[InputButtons] = ButtonLeft
+	mem[InputModifiers] = ModShift
+	mem[InputWheelY] = math.Float32bits(-3)
+	pressKey(mem, 'A', KeyDown, ModShift)
+	pressKey(mem, 'A', KeyUp, 0)
+
+	s.Read(&in)
+	if in.X != 12.5 || in.Y != 40 || in.Modifiers != ModShift {
+		t.Errorf("Wrong pointer, got %+v", in)
+	}
+	if in.Pressed != ButtonLeft || in.Released != 0 {
+		t.Errorf("Left button should be pressed, got %b %b", in.Pressed, in.Released)
+	}
+	if in.WheelY != -3 || mem[InputWheelY] != 0 {
+		t.Errorf("Wheel should be read then reset, got %v", in.WheelY)
+	}
+	want := []KeyEvent{{'A', KeyDown, ModShift}, {'A', KeyUp, 0}}
+	if len(in.Keys) != 2 || in.Keys[0] != want[0] || in.Keys[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, in.Keys)
+	}
+
+	// Nothing new: no edge, no wheel, no keys
+	s.Read(&in)
+	if in.Pressed != 0 || in.WheelY != 0 || len(in.Keys) != 0 {
+		t.Errorf("Second read should be quiet, got %+v", in)
+	}
+
+	mem[InputButtons] = 0
+	s.Read(&in)
+	if in.Released != ButtonLeft {
+		t.Errorf("Left button should be released, got %b", in.Released)
+	}
+}
+
+func TestInputState_KeyOverflow(t *testing.T) {
+	s := NewInputState()
+	mem := jsView(s)
+	var in Input
+
+	// Near the uint32 limit the counters wrap
+	mem[InputKeyWrite], mem[InputKeyRead] = math.MaxUint32-4, math.MaxUint32-4
+	for code := range uint32(InputKeySlots + 10) {
+		pressKey(mem, code, KeyDown, 0)
+	}
+
+	s.Read(&in)
+	if len(in.Keys) != InputKeySlots || in.Keys[0].Code != 10 || in.Keys[InputKeySlots-1].Code != InputKeySlots+9 {
+		t.Errorf("Only the latest %d events should be kept, got %v", InputKeySlots, in.Keys)
+	}
+}
@@ -0,0 +1,55 @@
+package protocol
+
+import "math"
+
+// Matrix là ma trận affine 2D theo đúng layout của OpSetMatrix:
+// [a, b, c, d, tx, ty]
+//
+//	| a  c  tx |
+//	| b  d  ty |
+//	| 0  0  1  |
+type Matrix [6]float32
+
+func IdentityMatrix() Matrix {
+	return Matrix{1, 0, 0, 1, 0, 0}
+}
+
+func TranslateMatrix(tx, ty float32) Matrix {
+	return Matrix{1, 0, 0, 1, tx, ty}
+}
+
+// RotateMatrix rotates by rad clockwise on screen (y points down)
+func RotateMatrix(rad float64) Matrix {
+	sin, cos := math.Sincos(rad)
+	return Matrix{float32(cos), float32(sin), float32(-sin), float32(cos), 0, 0}
+}
+
+// Multiply returns m * other, so other is applied first
+// World = Parent.Multiply(Local)
+func (m Matrix) Multiply(other Matrix) Matrix {
+	return Matrix{
+		m[0]*other[0] + m[2]*other[1],
+		m[1]*other[0] + m[3]*other[1],
+		m[0]*other[2] + m[2]*other[3],
+		m[1]*other[2] + m[3]*other[3],
+		m[0]*other[4] + m[2]*other[5] + m[4],
+		m[1]*other[4] + m[3]*other[5] + m[5],
+	}
+}
+
+// Apply transforms point (x, y) by m
+func (m Matrix) Apply(x, y float32) (float32, float32) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// Invert returns the inverse of m, false if m squashes space
+// to a line or a point
+func (m Matrix) Invert() (Matrix, bool) {
+	det := m[0]*m[3] - m[1]*m[2]
+	if det == 0 {
+		return Matrix{}, false
+	}
+
+	a, b, c, d := m[3]/det, -m[1]/det, -m[2]/det, m[0]/det
+	return Matrix{a, b, c, d, -(a*m[4] + c*m[5]), -(b*m[4] + d*m[5])}, true
+}
@@ -44,3 +44,12 @@ const (
 	OpSetFont  OpCode = 0x52 // Cài đặt Font chữ + Size
 	OpDrawText OpCode = 0x53 // Vẽ Text (theo ID chuỗi)
 )
+
+// DrawMode says whether a primitive is filled, stroked or both
+// with the current fill and stroke state
+type DrawMode uint32
+
+const (
+	DrawFill DrawMode = 1 << iota
+	DrawStroke
+)
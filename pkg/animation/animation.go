@@ -0,0 +1,232 @@
+// Package animation runs keyframe, spring and momentum animations of
+// numeric properties.
+// It knows nothing about the scene: every animation pushes its value
+// through Apply, the engine decides what the value drives.
+package animation
+
+import (
+	"math"
+	"sort"
+)
+
+// Kind says how the values of an animation are interpolated
+type Kind uint8
+
+const (
+	KindFloat Kind = iota
+	// Values hold a packed RGBA color (protocol.Color), every
+	// channel is interpolated on its own
+	KindColor
+)
+
+// Repeat an animation forever
+const Infinite = -1
+
+type Keyframe struct {
+	// Position in the animation, from 0 to 1
+	Offset float32
+	// float64 holds a packed color exactly
+	Value float64
+	// Easing of the segment up to the next keyframe, nil is linear
+	Easing Easing
+}
+
+// Animation plays its keyframes over Duration seconds
+type Animation struct {
+	Kind      Kind
+	Keyframes []Keyframe
+
+	Duration float32
+	// Seconds before the first frame, the value is not applied meanwhile
+	Delay float32
+	// Number of runs, 0 is the same as 1, Infinite never ends
+	Iterations int
+	// Odd runs play backwards
+	Alternate bool
+
+	// Receives the value of every tick, the last value is kept
+	// on the target once the animation is done
+	Apply func(value float64)
+
+	elapsed float32
+}
+
+// Done reports whether the animation played all its iterations
+func (a *Animation) Done() bool {
+	if a.Iterations == Infinite {
+		return false
+	}
+	return a.elapsed >= a.Delay+a.Duration*float32(max(a.Iterations, 1))
+}
+
+// progress returns the position in the keyframes at the elapsed time,
+// false while the animation is delayed
+func (a *Animation) progress() (float32, bool) {
+	t := a.elapsed - a.Delay
+	if t < 0 {
+		return 0, false
+	}
+	if a.Duration <= 0 || a.Done() {
+		// Last frame of the last run
+		last := max(a.Iterations, 1) - 1
+		if a.Alternate && last%2 == 1 {
+			return 0, true
+		}
+		return 1, true
+	}
+
+	run := int(t / a.Duration)
+	p := (t - float32(run)*a.Duration) / a.Duration
+	if a.Alternate && run%2 == 1 {
+		p = 1 - p
+	}
+	return p, true
+}
+
+// Value samples the keyframes at progress p
+func (a *Animation) Value(p float32) float64 {
+	keys := a.Keyframes
+	switch len(keys) {
+	case 0:
+		return 0
+	case 1:
+		return keys[0].Value
+	}
+
+	if p <= keys[0].Offset {
+		return keys[0].Value
+	}
+
+	// First keyframe after p, the segment starts right before it
+	i := sort.Search(len(keys), func(i int) bool { return keys[i].Offset > p })
+	if i == len(keys) {
+		return keys[len(keys)-1].Value
+	}
+
+	from, to := keys[i-1], keys[i]
+	local := float32(1)
+	if span := to.Offset - from.Offset; span > 0 {
+		local = (p - from.Offset) / span
+	}
+
+	ease := from.Easing
+	if ease == nil {
+		ease = Linear
+	}
+	return interpolate(a.Kind, from.Value, to.Value, float64(ease(local)))
+}
+
+// advance moves the animation dt seconds forward and applies its value
+func (a *Animation) advance(dt float32) bool {
+	a.elapsed += dt
+	p, ok := a.progress()
+	if ok {
+		a.Apply(a.Value(p))
+	}
+	return ok
+}
+
+func interpolate(kind Kind, from, to, t float64) float64 {
+	if kind != KindColor {
+		return from + (to-from)*t
+	}
+
+	// Channel by channel, clamped for easings that overshoot
+	c0, c1 := uint32(from), uint32(to)
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		v0 := float64(c0 >> shift & 0xFF)
+		v1 := float64(c1 >> shift & 0xFF)
+		v := math.Round(v0 + (v1-v0)*t)
+		out |= uint32(math.Max(0, math.Min(255, v))) << shift
+	}
+	return float64(out)
+}
+
+// player is anything the timeline runs: keyframes, springs, momentum
+type player interface {
+	// advance moves dt seconds forward and applies the value,
+	// false if nothing was applied (still delayed)
+	advance(dt float32) bool
+	Done() bool
+}
+
+// Timeline holds the running animations
+type Timeline struct {
+	animations map[uint32]player
+	// Play order, so animations of the same property apply
+	// in the order they started
+	order  []uint32
+	nextID uint32
+}
+
+func NewTimeline() *Timeline {
+	return &Timeline{animations: make(map[uint32]player)}
+}
+
+// Add starts a, keyframes are sorted by offset. Returns its ID, never 0.
+func (tl *Timeline) Add(a *Animation) uint32 {
+	sort.SliceStable(a.Keyframes, func(i, j int) bool {
+		return a.Keyframes[i].Offset < a.Keyframes[j].Offset
+	})
+	return tl.add(a)
+}
+
+// AddSpring starts s, see Add
+func (tl *Timeline) AddSpring(s *SpringAnimation) uint32 {
+	return tl.add(s)
+}
+
+// AddMomentum starts m, see Add
+func (tl *Timeline) AddMomentum(m *Momentum) uint32 {
+	return tl.add(m)
+}
+
+func (tl *Timeline) add(p player) uint32 {
+	tl.nextID++
+	tl.animations[tl.nextID] = p
+	tl.order = append(tl.order, tl.nextID)
+	return tl.nextID
+}
+
+// Cancel stops animation id where it is
+func (tl *Timeline) Cancel(id uint32) bool {
+	if _, ok := tl.animations[id]; !ok {
+		return false
+	}
+	delete(tl.animations, id)
+	return true
+}
+
+// Len is the number of animations still running
+func (tl *Timeline) Len() int {
+	return len(tl.animations)
+}
+
+// Step advances every animation by dt seconds and applies their values,
+// finished animations apply their last value and are removed. Returns
+// whether any value was applied.
+func (tl *Timeline) Step(dt float32) bool {
+	applied := false
+
+	order := tl.order[:0]
+	for _, id := range tl.order {
+		a, ok := tl.animations[id]
+		if !ok {
+			continue
+		}
+
+		if a.advance(dt) {
+			applied = true
+		}
+
+		if a.Done() {
+			delete(tl.animations, id)
+			continue
+		}
+		order = append(order, id)
+	}
+	tl.order = order
+
+	return applied
+}
@@ -0,0 +1,103 @@
+package animation
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func TestEasing(t *testing.T) {
+	for name, ease := range map[string]Easing{
+		"linear": Linear, "ease": Ease, "ease-in": EaseIn, "ease-out": EaseOut, "ease-in-out": EaseInOut,
+	} {
+		if ease(0) != 0 || ease(1) != 1 {
+			t.Errorf("%s should go from 0 to 1", name)
+		}
+	}
+
+	// Symmetric curve crosses the middle at the middle
+	if v := EaseInOut(0.5); !near(float64(v), 0.5) {
+		t.Errorf("ease-in-out(0.5) expected 0.5, got %v", v)
+	}
+	if EaseIn(0.25) >= 0.25 || EaseOut(0.25) <= 0.25 {
+		t.Errorf("ease-in should start slow and ease-out fast")
+	}
+
+	// Control points above 1 overshoot
+	back := CubicBezier(0.3, 1.5, 0.7, 1.5)
+	if back(0.5) <= 1 {
+		t.Errorf("Expected overshoot, got %v", back(0.5))
+	}
+}
+
+func TestAnimation_Value(t *testing.T) {
+	a := &Animation{Keyframes: []Keyframe{
+		{Offset: 0, Value: 0},
+		{Offset: 0.5, Value: 100, Easing: EaseIn},
+		{Offset: 1, Value: 50},
+	}}
+
+	cases := []struct {
+		p    float32
+		want float64
+	}{{-1, 0}, {0, 0}, {0.25, 50}, {0.5, 100}, {1, 50}, {2, 50}}
+	for _, c := range cases {
+		if v := a.Value(c.p); !near(v, c.want) {
+			t.Errorf("Value(%v) expected %v, got %v", c.p, c.want, v)
+		}
+	}
+	if v := a.Value(0.75); v <= 75 {
+		t.Errorf("ease-in segment should lag behind linear, got %v", v)
+	}
+
+	color := &Animation{Kind: KindColor, Keyframes: []Keyframe{
+		{Offset: 0, Value: float64(0xFF0000FF)},
+		{Offset: 1, Value: float64(0xFFFF0000)},
+	}}
+	if v := uint32(color.Value(0.5)); v != 0xFF800080 {
+		t.Errorf("Colors interpolate per channel, got %08x", v)
+	}
+}
+
+func TestTimeline_Step(t *testing.T) {
+	tl := NewTimeline()
+
+	var values []float64
+	tl.Add(&Animation{
+		Keyframes:  []Keyframe{{Offset: 1, Value: 10}, {Offset: 0, Value: 0}},
+		Duration:   1,
+		Delay:      0.5,
+		Iterations: 2,
+		Alternate:  true,
+		Apply:      func(v float64) { values = append(values, v) },
+	})
+
+	if tl.Step(0.25) || len(values) != 0 {
+		t.Errorf("Delayed animation should not apply")
+	}
+
+	for _, dt := range []float32{0.5, 0.5, 0.5, 0.5} {
+		tl.Step(dt)
+	}
+	want := []float64{2.5, 7.5, 7.5, 2.5}
+	for i := range want {
+		if i >= len(values) || !near(values[i], want[i]) {
+			t.Fatalf("Expected %v, got %v", want, values)
+		}
+	}
+
+	// Second run ends backwards, at the first keyframe
+	tl.Step(1)
+	if tl.Len() != 0 || values[len(values)-1] != 0 {
+		t.Errorf("Expected done at 0, got %v with %d running", values, tl.Len())
+	}
+
+	id := tl.Add(&Animation{Duration: 1, Iterations: Infinite, Apply: func(float64) {}})
+	tl.Step(100)
+	if tl.Len() != 1 || !tl.Cancel(id) || tl.Cancel(id) {
+		t.Errorf("Infinite animation runs until cancelled")
+	}
+}
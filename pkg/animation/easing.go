@@ -0,0 +1,97 @@
+package animation
+
+import "math"
+
+// Easing maps the linear progress t of a segment (0 to 1)
+// to the eased progress, which may overshoot
+type Easing func(t float32) float32
+
+// Linear is also used when a keyframe has no easing
+func Linear(t float32) float32 {
+	return t
+}
+
+// CSS easing keywords
+var (
+	Ease      = CubicBezier(0.25, 0.1, 0.25, 1)
+	EaseIn    = CubicBezier(0.42, 0, 1, 1)
+	EaseOut   = CubicBezier(0, 0, 0.58, 1)
+	EaseInOut = CubicBezier(0.42, 0, 0.58, 1)
+)
+
+// CubicBezier returns the easing of the CSS cubic-bezier() function,
+// the curve goes from (0, 0) to (1, 1) through two control points.
+// x1 and x2 are clamped to [0, 1] so the curve stays a function of t.
+func CubicBezier(x1, y1, x2, y2 float32) Easing {
+	b := bezier{
+		x1: math.Max(0, math.Min(1, float64(x1))), y1: float64(y1),
+		x2: math.Max(0, math.Min(1, float64(x2))), y2: float64(y2),
+	}
+
+	return func(t float32) float32 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+		return float32(b.y(b.solve(float64(t))))
+	}
+}
+
+// bezier solves the curve in float64, the error of float32 shows
+// at the ends of long animations
+type bezier struct {
+	x1, y1, x2, y2 float64
+}
+
+func (b bezier) x(s float64) float64 {
+	return cubic(b.x1, b.x2, s)
+}
+
+func (b bezier) y(s float64) float64 {
+	return cubic(b.y1, b.y2, s)
+}
+
+// cubic is one coordinate of the curve at parameter s, p0 = 0, p3 = 1
+func cubic(p1, p2, s float64) float64 {
+	inv := 1 - s
+	return 3*inv*inv*s*p1 + 3*inv*s*s*p2 + s*s*s
+}
+
+func (b bezier) dx(s float64) float64 {
+	inv := 1 - s
+	return 3*inv*inv*b.x1 + 6*inv*s*(b.x2-b.x1) + 3*s*s*(1-b.x2)
+}
+
+// solve finds the curve parameter whose x is t: Newton first, then
+// bisection where the slope is too flat for Newton to converge
+func (b bezier) solve(t float64) float64 {
+	const epsilon = 1e-7
+
+	s := t
+	for range 8 {
+		diff := b.x(s) - t
+		if math.Abs(diff) < epsilon {
+			return s
+		}
+		d := b.dx(s)
+		if math.Abs(d) < 1e-6 {
+			break
+		}
+		s -= diff / d
+	}
+
+	lo, hi := 0.0, 1.0
+	s = t
+	for range 64 {
+		diff := b.x(s) - t
+		if math.Abs(diff) < epsilon {
+			break
+		}
+		if diff > 0 {
+			hi = s
+		} else {
+			lo = s
+		}
+		s = (lo + hi) / 2
+	}
+	return s
+}
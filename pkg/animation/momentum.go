@@ -0,0 +1,70 @@
+package animation
+
+import "math"
+
+// DefaultFriction loses about 0.2% of the velocity per millisecond,
+// the deceleration of native scroll views
+const DefaultFriction = 2
+
+// DefaultBounce pulls an overscrolled value back without oscillating
+var DefaultBounce = Spring{Stiffness: 400, Damping: 40, Mass: 1}
+
+// Momentum carries a value on after a fling, slowing down by friction.
+// Past [Min, Max] the Bounce spring pulls it back to the bound it
+// crossed (rubber banding).
+type Momentum struct {
+	Value    float64
+	Velocity float64
+	// Exponential decay of the velocity per second, 0 is DefaultFriction
+	Friction float64
+	// Use ±Inf for no bound
+	Min, Max float64
+	// Zero is DefaultBounce
+	Bounce Spring
+
+	// See Animation.Apply
+	Apply func(value float64)
+
+	clock clock
+	done  bool
+}
+
+func (m *Momentum) Done() bool {
+	return m.done
+}
+
+func (m *Momentum) advance(dt float32) bool {
+	friction := m.Friction
+	if friction <= 0 {
+		friction = DefaultFriction
+	}
+	decay := math.Exp(-friction * physicsStep)
+
+	bounce := m.Bounce
+	if bounce == (Spring{}) {
+		bounce = DefaultBounce
+	}
+
+	for range m.clock.advance(dt) {
+		bound := math.Max(m.Min, math.Min(m.Max, m.Value))
+		if m.Value == bound {
+			m.Velocity *= decay
+			m.Value += m.Velocity * physicsStep
+			if math.Abs(m.Velocity) < restSpeed {
+				m.Velocity, m.done = 0, true
+				break
+			}
+			continue
+		}
+
+		x, v := bounce.step(m.Value-bound, m.Velocity)
+		m.Value, m.Velocity = bound+x, v
+		if math.Abs(x) < restDelta && math.Abs(v) < restSpeed {
+			m.Value, m.Velocity, m.done = bound, 0, true
+			break
+		}
+	}
+
+	m.Apply(m.Value)
+	return true
+}
@@ -0,0 +1,140 @@
+package animation
+
+import "math"
+
+// Fixed step of the physics integrator in seconds. Frames only decide how
+// many steps run, so the same elapsed time always gives the same value
+// whatever the frame rate.
+const physicsStep = 1.0 / 240
+
+// A physics animation settles once closer than restDelta to where it
+// goes and slower than restSpeed units per second
+const (
+	restDelta = 0.01
+	restSpeed = 0.1
+)
+
+// clock turns frame times into whole integrator steps
+type clock struct {
+	elapsed float64
+	steps   int
+}
+
+// advance returns how many steps dt seconds add
+func (c *clock) advance(dt float32) int {
+	c.elapsed += float64(dt)
+	// Epsilon absorbs the rounding of frame times like 1/60
+	target := int(math.Floor(c.elapsed/physicsStep + 1e-6))
+	n := target - c.steps
+	c.steps = target
+	return n
+}
+
+// Spring is a damped harmonic oscillator pulling a value to its target
+type Spring struct {
+	Stiffness float64
+	Damping   float64
+	// 0 is 1
+	Mass float64
+}
+
+// DefaultSpring slightly overshoots, the feel of most UI springs
+var DefaultSpring = Spring{Stiffness: 170, Damping: 26, Mass: 1}
+
+// CriticalDamping is the damping at which s returns fastest
+// without overshooting
+func (s Spring) CriticalDamping() float64 {
+	return 2 * math.Sqrt(s.Stiffness*s.mass())
+}
+
+func (s Spring) mass() float64 {
+	if s.Mass <= 0 {
+		return 1
+	}
+	return s.Mass
+}
+
+func (s Spring) accel(x, v float64) float64 {
+	return (-s.Stiffness*x - s.Damping*v) / s.mass()
+}
+
+// step advances displacement x and velocity v by one physicsStep (RK4)
+func (s Spring) step(x, v float64) (float64, float64) {
+	const h = physicsStep
+
+	x1, v1 := v, s.accel(x, v)
+	x2, v2 := v+v1*h/2, s.accel(x+x1*h/2, v+v1*h/2)
+	x3, v3 := v+v2*h/2, s.accel(x+x2*h/2, v+v2*h/2)
+	x4, v4 := v+v3*h, s.accel(x+x3*h, v+v3*h)
+
+	x += (x1 + 2*x2 + 2*x3 + x4) * h / 6
+	v += (v1 + 2*v2 + 2*v3 + v4) * h / 6
+	return x, v
+}
+
+// SpringAnimation moves a value from From to To following Spring
+type SpringAnimation struct {
+	Spring
+	Kind     Kind
+	From, To float64
+	// Starting speed in units per second, ignored for colors
+	Velocity float64
+	// Seconds before the spring is released
+	Delay float32
+
+	// See Animation.Apply
+	Apply func(value float64)
+
+	waited  float32
+	started bool
+	clock   clock
+	// Displacement from To and velocity. Colors move their progress
+	// from -1 to 0 instead so every channel arrives together.
+	x, v float64
+	done bool
+}
+
+func (s *SpringAnimation) Done() bool {
+	return s.done
+}
+
+func (s *SpringAnimation) advance(dt float32) bool {
+	if !s.started {
+		s.waited += dt
+		if s.waited < s.Delay {
+			return false
+		}
+		dt = s.waited - s.Delay
+		s.started = true
+
+		if s.Kind == KindColor {
+			s.x = -1
+		} else {
+			s.x, s.v = s.From-s.To, s.Velocity
+		}
+	}
+
+	delta := restDelta
+	if s.Kind == KindColor {
+		// A hundredth of progress would show on the channels
+		delta = 1.0 / 512
+	}
+
+	for range s.clock.advance(dt) {
+		s.x, s.v = s.step(s.x, s.v)
+		if math.Abs(s.x) < delta && math.Abs(s.v) < restSpeed {
+			s.x, s.v, s.done = 0, 0, true
+			break
+		}
+	}
+
+	s.Apply(s.value())
+	return true
+}
+
+func (s *SpringAnimation) value() float64 {
+	if s.Kind == KindColor {
+		return interpolate(KindColor, s.From, s.To, 1+s.x)
+	}
+	return s.To + s.x
+}
@@ -0,0 +1,116 @@
+package animation
+
+import (
+	"math"
+	"testing"
+)
+
+// run steps p with frames of dt until done or seconds have passed,
+// returning the applied values
+func run(p player, dt float32, seconds float64) []float64 {
+	var values []float64
+	switch p := p.(type) {
+	case *SpringAnimation:
+		p.Apply = func(v float64) { values = append(values, v) }
+	case *Momentum:
+		p.Apply = func(v float64) { values = append(values, v) }
+	}
+
+	for i := 0; float64(i)*float64(dt) < seconds && !p.Done(); i++ {
+		p.advance(dt)
+	}
+	return values
+}
+
+func TestSpring_CriticallyDamped(t *testing.T) {
+	spring := Spring{Stiffness: 100, Mass: 1}
+	spring.Damping = spring.CriticalDamping()
+
+	values := run(&SpringAnimation{Spring: spring, From: 0, To: 100}, 0.05, 0.5)
+
+	// x(t) = x0 (1 + ωt) e^(-ωt) with ω = 10
+	for i, v := range values {
+		wt := 10 * 0.05 * float64(i+1)
+		want := 100 - 100*(1+wt)*math.Exp(-wt)
+		if math.Abs(v-want) > 0.01 {
+			t.Errorf("t=%.2f expected %.2f, got %.2f", 0.05*float64(i+1), want, v)
+		}
+		if v > 100 {
+			t.Errorf("Critically damped spring should not overshoot, got %v", v)
+		}
+	}
+}
+
+func TestSpring_Deterministic(t *testing.T) {
+	newSpring := func() *SpringAnimation {
+		return &SpringAnimation{Spring: DefaultSpring, From: 0, To: 100, Velocity: -500}
+	}
+
+	// Frames of 60Hz and 30Hz land on the same values every 1/30s
+	at60 := run(newSpring(), 1.0/60, 0.5)
+	at30 := run(newSpring(), 1.0/30, 0.5)
+	for i := range at30 {
+		if at30[i] != at60[2*i+1] {
+			t.Fatalf("Frame %d: 30Hz %v, 60Hz %v", i, at30[i], at60[2*i+1])
+		}
+	}
+
+	again := run(newSpring(), 1.0/60, 0.5)
+	for i := range at60 {
+		if again[i] != at60[i] {
+			t.Fatalf("Same inputs gave different frames")
+		}
+	}
+}
+
+func TestSpring_Settles(t *testing.T) {
+	s := &SpringAnimation{Spring: Spring{Stiffness: 300, Damping: 10}, From: 0, To: 100, Delay: 0.1}
+	values := run(s, 1.0/60, 10)
+
+	if !s.Done() || values[len(values)-1] != 100 {
+		t.Fatalf("Spring should settle exactly on its target, got %v", values[len(values)-1])
+	}
+
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	if peak <= 100 {
+		t.Errorf("Underdamped spring should overshoot")
+	}
+
+	color := &SpringAnimation{Spring: DefaultSpring, Kind: KindColor, From: float64(0xFF000000), To: float64(0xFFFFFFFF)}
+	values = run(color, 1.0/60, 10)
+	if uint32(values[len(values)-1]) != 0xFFFFFFFF {
+		t.Errorf("Color spring should end on its target, got %08x", uint32(values[len(values)-1]))
+	}
+}
+
+func TestMomentum(t *testing.T) {
+	inf := math.Inf(1)
+
+	// Exponential decay travels v0 / friction
+	free := &Momentum{Velocity: 1000, Min: -inf, Max: inf}
+	values := run(free, 1.0/60, 20)
+	if got := values[len(values)-1]; !free.Done() || math.Abs(got-500) > 5 {
+		t.Errorf("Expected to glide about 500, got %v", got)
+	}
+
+	// Flung past the end, it overshoots then rests on the bound
+	bounded := &Momentum{Value: 50, Velocity: 1000, Min: 0, Max: 200}
+	values = run(bounded, 1.0/60, 20)
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	if !bounded.Done() || values[len(values)-1] != 200 || peak <= 200 {
+		t.Errorf("Expected rubber band back to 200, peak %v end %v", peak, values[len(values)-1])
+	}
+
+	again := run(&Momentum{Value: 50, Velocity: 1000, Min: 0, Max: 200}, 1.0/60, 20)
+	for i := range values {
+		if again[i] != values[i] {
+			t.Fatalf("Same inputs gave different frames")
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package engine
+
+import (
+	"math"
+	"unsafe"
+
+	"engo/pkg/animation"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+// Time budget of the reconciler in one Update, the rest of the
+// work continues on the next frame
+const frameBudgetMs = 10
+
+// Easing codes shared with JS
+const (
+	EASE_LINEAR int32 = iota
+	EASE
+	EASE_IN
+	EASE_OUT
+	EASE_IN_OUT
+	// Keyframes only, the curve follows the easing code
+	EASE_CUBIC_BEZIER
+)
+
+// Words per keyframe of AnimateKeyframes:
+// [offset, value, easing, x1, y1, x2, y2]
+const keyframeWords = 7
+
+func easingOf(code int32) animation.Easing {
+	switch code {
+	case EASE:
+		return animation.Ease
+	case EASE_IN:
+		return animation.EaseIn
+	case EASE_OUT:
+		return animation.EaseOut
+	case EASE_IN_OUT:
+		return animation.EaseInOut
+	}
+	return animation.Linear
+}
+
+// animatedProp returns the current value of propCode on node and how it
+// interpolates, false if the property can't be animated
+func animatedProp(node *scene.Node, propCode int32) (float64, animation.Kind, bool) {
+	x, y, w, h := node.Box()
+
+	switch propCode {
+	case PROP_X:
+		return float64(x), animation.KindFloat, true
+	case PROP_Y:
+		return float64(y), animation.KindFloat, true
+	case PROP_WIDTH:
+		return float64(w), animation.KindFloat, true
+	case PROP_HEIGHT:
+		return float64(h), animation.KindFloat, true
+	}
+
+	if propCode == PROP_OPACITY || propCode == PROP_ASPECT_RATIO {
+		if node.Style == nil {
+			return 0, animation.KindFloat, false
+		}
+		if propCode == PROP_OPACITY {
+			return float64(node.Style.Opacity), animation.KindFloat, true
+		}
+		return float64(node.Style.AspectRatio), animation.KindFloat, true
+	}
+
+	switch props := node.Props.(type) {
+	case *scene.RectProps:
+		switch propCode {
+		case PROP_RADIUS:
+			return float64(props.CornerRadius[0]), animation.KindFloat, true
+		case PROP_FILL:
+			return float64(props.Fill), animation.KindColor, true
+		case PROP_STROKE:
+			return float64(props.Stroke), animation.KindColor, true
+		}
+	case *scene.TextProps:
+		if propCode == PROP_FILL {
+			return float64(props.Fill), animation.KindColor, true
+		}
+	}
+
+	return 0, animation.KindFloat, false
+}
+
+// applyAnimatedProp writes an animated value without going through the
+// history, animations are not edits of the document
+func (e *Engine) applyAnimatedProp(node *scene.Node, propCode int32, value float64) {
+	switch propCode {
+	case PROP_FILL, PROP_STROKE:
+		color := uint32(value)
+		switch props := node.Props.(type) {
+		case *scene.RectProps:
+			if propCode == PROP_FILL {
+				props.Fill = color
+			} else {
+				props.Stroke = color
+			}
+		case *scene.TextProps:
+			props.Fill = color
+		}
+		e.markAnimated(node, scene.FlagContentDirty)
+		return
+	}
+
+	if node.Style == nil {
+		return
+	}
+	setFloatProp(node, propCode, float32(value))
+
+	switch propCode {
+	case PROP_X, PROP_Y, PROP_WIDTH, PROP_HEIGHT, PROP_ASPECT_RATIO:
+		// Computed box is stale until the layout of this tick
+		node.ComputedStyle = nil
+		e.markAnimated(node, scene.FlagLayoutDirty)
+	default:
+		e.markAnimated(node, scene.FlagContentDirty)
+	}
+}
+
+// animatedNode is a node an animation changed while a pass was running
+type animatedNode struct {
+	node *scene.Node
+	flag scene.NodeFlag
+}
+
+// markAnimated marks node dirty for this frame of an animation. A pass
+// already running is not restarted, a large scene would then never
+// commit. It may have gone past node, so node is marked again once the
+// pass commits, see update.
+func (e *Engine) markAnimated(node *scene.Node, flag scene.NodeFlag) {
+	node.MarkDirty(flag)
+	if e.reconciler.WipRoot != nil {
+		e.animated = append(e.animated, animatedNode{node, flag})
+	}
+}
+
+// animate plays a on propCode of node, the kind of a follows the
+// property. Returns 0 if the property can't be animated.
+func (e *Engine) animate(node *scene.Node, propCode int32, a *animation.Animation) uint32 {
+	_, kind, ok := animatedProp(node, propCode)
+	if !ok {
+		return 0
+	}
+
+	a.Kind = kind
+	a.Apply = e.propApplier(node.ID, propCode)
+	return e.animations.Add(a)
+}
+
+// animateSpring is animate for springs, From is the current value
+func (e *Engine) animateSpring(node *scene.Node, propCode int32, s *animation.SpringAnimation) uint32 {
+	from, kind, ok := animatedProp(node, propCode)
+	if !ok {
+		return 0
+	}
+
+	s.Kind, s.From = kind, from
+	s.Apply = e.propApplier(node.ID, propCode)
+	return e.animations.AddSpring(s)
+}
+
+// propApplier writes animated values to propCode of node id. The node is
+// looked up every tick, it may be removed meanwhile.
+func (e *Engine) propApplier(id uint32, propCode int32) func(float64) {
+	return func(value float64) {
+		if node := e.findNode(id); node != nil {
+			e.applyAnimatedProp(node, propCode, value)
+		}
+	}
+}
+
+// scrollRange is how far the content of node reaches past its box,
+// fixed children don't scroll and are left out
+func scrollRange(node *scene.Node) (float32, float32) {
+	_, _, w, h := node.Box()
+
+	var right, bottom float32
+	for _, child := range node.Children {
+		if child.Style != nil && child.Style.Position == style.PositionFixed {
+			continue
+		}
+		x, y, cw, ch := child.Box()
+		right, bottom = max(right, x+cw), max(bottom, y+ch)
+	}
+
+	padding := node.Style.Padding
+	right += padding.Right.Resolve(w)
+	bottom += padding.Bottom.Resolve(h)
+	return max(0, right-w), max(0, bottom-h)
+}
+
+// fling starts inertial scrolling of node at velocity (pixel per second),
+// replacing a fling still running on it
+func (e *Engine) fling(node *scene.Node, vx, vy float32) {
+	e.stopFling(node)
+
+	maxX, maxY := scrollRange(node)
+	id := node.ID
+
+	axis := func(value, velocity, limit float32, apply func(target *scene.Node, v float32)) uint32 {
+		return e.animations.AddMomentum(&animation.Momentum{
+			Value:    float64(value),
+			Velocity: float64(velocity),
+			Max:      float64(limit),
+			Apply: func(v float64) {
+				if target := e.findNode(id); target != nil {
+					apply(target, float32(v))
+				}
+			},
+		})
+	}
+
+	e.flings[id] = [2]uint32{
+		axis(node.ScrollX, vx, maxX, func(target *scene.Node, v float32) {
+			e.setScroll(target, v, target.ScrollY)
+			e.markAnimated(target, scene.FlagLayoutDirty)
+		}),
+		axis(node.ScrollY, vy, maxY, func(target *scene.Node, v float32) {
+			e.setScroll(target, target.ScrollX, v)
+			e.markAnimated(target, scene.FlagLayoutDirty)
+		}),
+	}
+}
+
+// stopFling cancels inertial scrolling of node, it stays where it is
+func (e *Engine) stopFling(node *scene.Node) {
+	if ids, ok := e.flings[node.ID]; ok {
+		e.animations.Cancel(ids[0])
+		e.animations.Cancel(ids[1])
+		delete(e.flings, node.ID)
+	}
+}
+
+// readKeyframes decodes count entries of the keyframe buffer, values are
+// float32 bits or packed colors depending on kind
+func readKeyframes(words []uint32, kind animation.Kind) []animation.Keyframe {
+	keys := make([]animation.Keyframe, 0, len(words)/keyframeWords)
+	for i := 0; i+keyframeWords <= len(words); i += keyframeWords {
+		entry := words[i : i+keyframeWords]
+
+		value := float64(math.Float32frombits(entry[1]))
+		if kind == animation.KindColor {
+			value = float64(entry[1])
+		}
+
+		ease := easingOf(int32(entry[2]))
+		if int32(entry[2]) == EASE_CUBIC_BEZIER {
+			ease = animation.CubicBezier(
+				math.Float32frombits(entry[3]), math.Float32frombits(entry[4]),
+				math.Float32frombits(entry[5]), math.Float32frombits(entry[6]))
+		}
+
+		keys = append(keys, animation.Keyframe{
+			Offset: math.Float32frombits(entry[0]),
+			Value:  value,
+			Easing: ease,
+		})
+	}
+	return keys
+}
+
+func (e *Engine) allocKeyframeBuffer(count int) uintptr {
+	size := count * keyframeWords
+	if cap(e.keyframeBuffer) < size {
+		e.keyframeBuffer = make([]uint32, size)
+	}
+	e.keyframeBuffer = e.keyframeBuffer[:size]
+
+	if size == 0 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(&e.keyframeBuffer[0]))
+}
+
+// update is one frame: input of the frame is read, animations move, the
+// reconciler picks the changes up within the frame budget, then the
+// frame is drawn
+func (e *Engine) update(dt float32) {
+	e.inputState.Read(&e.input)
+	e.updateViewport()
+	e.dragMarquee()
+	e.dragTransform()
+
+	// A running pass goes on, it takes the changes it has not reached
+	if e.animations.Step(dt) && e.reconciler.WipRoot == nil {
+		e.reconciler.ScheduleUpdate(e.rootNode)
+	}
+
+	if !e.reconciler.WorkLoop(frameBudgetMs) && len(e.animated) > 0 {
+		// Committed, what changed behind the pass goes into the next one
+		for _, a := range e.animated {
+			a.node.MarkDirty(a.flag)
+		}
+		e.animated = e.animated[:0]
+		e.reconciler.ScheduleUpdate(e.rootNode)
+	}
+	e.render()
+}
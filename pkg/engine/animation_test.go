@@ -0,0 +1,206 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"engo/internal/protocol"
+	"engo/pkg/fiber"
+	"engo/pkg/scene"
+)
+
+const animationDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":0,"top":0,"width":100,"height":100},"props":{"fill":4278190080}}
+]}}`
+
+func TestAnimate_Tween(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(animationDocument))
+	Update(0)
+	rect := engine.findNode(2)
+
+	id := Animate(2, PROP_X, math.Float32bits(100), 1, 0, EASE_LINEAR)
+	if id == 0 {
+		t.Fatalf("Animate should start on an existing node")
+	}
+
+	Update(0.25)
+	if x, _, _, _ := rect.Box(); x != 25 {
+		t.Errorf("Expected x 25 after a quarter, got %v", x)
+	}
+
+	Update(1)
+	if x, _, _, _ := rect.Box(); x != 100 {
+		t.Errorf("Expected x 100 once done, got %v", x)
+	}
+	if engine.animations.Len() != 0 || CancelAnimation(id) {
+		t.Errorf("Finished animation should be removed")
+	}
+
+	// Every tick ends a fresh frame
+	data := engine.commandBuffer.Data
+	if len(data) == 0 || protocol.OpCode(data[len(data)-1]&0xFF) != protocol.OpEof {
+		t.Errorf("Command buffer should end with Eof, got %v", data)
+	}
+
+	// Animations are not edits
+	if Undo() {
+		t.Errorf("Animation should not be recorded in history")
+	}
+}
+
+func TestAnimate_Keyframes(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(animationDocument))
+	rect := engine.findNode(2).Props.(*scene.RectProps)
+
+	// Black to white and back to black, alternating forever
+	white := protocol.Color(255, 255, 255, 255)
+	AllocKeyframeBuffer(2)
+	copy(engine.keyframeBuffer, []uint32{
+		0, 0xFF000000, uint32(EASE_LINEAR), 0, 0, 0, 0,
+		math.Float32bits(1), white, uint32(EASE_LINEAR), 0, 0, 0, 0,
+	})
+
+	id := AnimateKeyframes(2, PROP_FILL, 2, 1, 0, -1, true)
+	if id == 0 {
+		t.Fatalf("AnimateKeyframes rejected the keyframes")
+	}
+
+	Update(0.5)
+	if rect.Fill != protocol.Color(128, 128, 128, 255) {
+		t.Errorf("Expected mid gray, got %08x", rect.Fill)
+	}
+	Update(1)
+	if rect.Fill != protocol.Color(128, 128, 128, 255) {
+		t.Errorf("Second run plays backwards, got %08x", rect.Fill)
+	}
+
+	if !CancelAnimation(id) {
+		t.Fatalf("Running animation should be cancelled")
+	}
+	Update(0.25)
+	if rect.Fill != protocol.Color(128, 128, 128, 255) {
+		t.Errorf("Cancelled animation should leave the value, got %08x", rect.Fill)
+	}
+
+	if Animate(2, PROP_MIN_WIDTH, 0, 1, 0, EASE_LINEAR) != 0 || Animate(99, PROP_X, 0, 1, 0, EASE_LINEAR) != 0 {
+		t.Errorf("Unsupported property or missing node should not animate")
+	}
+}
+
+func TestAnimateSpring(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(animationDocument))
+	Update(0)
+	rect := engine.findNode(2)
+
+	if AnimateSpring(2, PROP_WIDTH, math.Float32bits(200), 170, 26, 1, 0) == 0 {
+		t.Fatalf("AnimateSpring should start on an existing node")
+	}
+
+	Update(0.1)
+	if _, _, w, _ := rect.Box(); w <= 100 || w >= 200 {
+		t.Errorf("Spring should be on its way, got width %v", w)
+	}
+
+	for range 300 {
+		Update(1.0 / 60)
+	}
+	if _, _, w, _ := rect.Box(); w != 200 || engine.animations.Len() != 0 {
+		t.Errorf("Spring should settle at 200, got width %v", w)
+	}
+}
+
+const scrollDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":0,"top":0,"width":100,"height":100,"overflow":2},"children":[
+		{"id":3,"type":"FRAME","style":{"left":0,"top":0,"width":100,"height":400}}
+	]}
+]}}`
+
+func TestScrollFling(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(scrollDocument))
+	Update(0)
+	frame := engine.findNode(2)
+
+	ScrollFling(2, 0, 400)
+	Update(0.25)
+	if frame.ScrollY <= 0 || frame.ScrollX != 0 {
+		t.Errorf("Fling should scroll down, got %v, %v", frame.ScrollX, frame.ScrollY)
+	}
+
+	// Grabbing the content stops the fling
+	SetScrollOffset(2, 0, 50)
+	Update(0.25)
+	if frame.ScrollY != 50 {
+		t.Errorf("SetScrollOffset should stop the fling, got %v", frame.ScrollY)
+	}
+
+	// Flung past the 300px of content, it rests on the end
+	ScrollFling(2, 0, 5000)
+	for range 600 {
+		Update(1.0 / 60)
+	}
+	if frame.ScrollY != 300 || engine.animations.Len() != 0 {
+		t.Errorf("Expected to rest at 300, got %v", frame.ScrollY)
+	}
+}
+
+// committedFiber finds the fiber of id in the committed tree
+func committedFiber(id uint32) *fiber.Fiber {
+	var find func(f *fiber.Fiber) *fiber.Fiber
+	find = func(f *fiber.Fiber) *fiber.Fiber {
+		for ; f != nil; f = f.Sibling {
+			if f.Key == id {
+				return f
+			}
+			if found := find(f.Child); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(engine.reconciler.CurrentRoot)
+}
+
+// A pass over a large board takes longer than a frame, animation frames
+// must not restart it
+func TestAnimate_LargeDocument(t *testing.T) {
+	if testing.Short() {
+		t.Skip("large document")
+	}
+
+	Init()
+	loadDocument(t, boardDocument(100))
+	engine.reconciler.WorkLoop(0)
+
+	// Frame 500 starts at (9900, 150)
+	Animate(500, PROP_X, math.Float32bits(0), 0.5, 0, EASE_LINEAR)
+	commits := 0
+	root := engine.reconciler.CurrentRoot
+	for range 30 {
+		Update(1.0 / 60)
+		if engine.reconciler.CurrentRoot != root {
+			root = engine.reconciler.CurrentRoot
+			commits++
+		}
+	}
+	if commits == 0 {
+		t.Fatalf("Animation frames never let the pass commit")
+	}
+	if x := committedFiber(500).LayoutX; x == 9900 {
+		t.Errorf("Committed box should have moved, got x %v", x)
+	}
+
+	// Last frame lands even if a pass was running when it was applied
+	for i := 0; i < 100 && engine.animations.Len() > 0; i++ {
+		Update(1.0 / 60)
+	}
+	for i := 0; i < 100 && (engine.reconciler.WipRoot != nil || len(engine.animated) > 0); i++ {
+		Update(1.0 / 60)
+	}
+	if x := committedFiber(500).LayoutX; x != 0 {
+		t.Errorf("Expected the final x 0 committed, got %v", x)
+	}
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+// resize gives the page the size of the view. Constraints only apply
+// once the page had a size, the first call just sets it.
+func (e *Engine) resize(width, height float32) {
+	e.viewport.Width, e.viewport.Height = width, height
+
+	root := e.rootNode
+	if root.Style == nil {
+		root.Style = style.NewStyle()
+	}
+
+	// Not undoable, the view size is not an edit of the document
+	edit := func(node *scene.Node, f func()) {
+		f()
+		e.afterEdit(node)
+	}
+	resize := func() {
+		root.Style.Width, root.Style.Height = layout.Px(width), layout.Px(height)
+	}
+
+	if _, _, w, h := root.Box(); w <= 0 || h <= 0 {
+		edit(root, resize)
+		return
+	}
+	e.resizeNode(root, edit, resize)
+}
+
+// resizeNode runs resize on node, then moves and stretches its children
+// by their constraints. Every touched node goes through edit.
+func (e *Engine) resizeNode(node *scene.Node, edit func(*scene.Node, func()), resize func()) {
+	_, _, oldW, oldH := node.Box()
+	edit(node, resize)
+	e.applyConstraints(node, oldW, oldH, edit)
+}
+
+// editSize records edit of propCode on node as one undo step. Width and
+// height edits of a node with children move and stretch them by their
+// constraints in the same step, whichever setter they come from.
+func (e *Engine) editSize(node *scene.Node, propCode int32, edit func()) {
+	if (propCode != PROP_WIDTH && propCode != PROP_HEIGHT) || !node.HasChildNodes() {
+		e.editNode(node, edit)
+		return
+	}
+
+	e.history.begin()
+	e.resizeNode(node, e.editNode, edit)
+	e.history.commit()
+}
+
+// applyConstraints follows the size change of parent from oldW x oldH
+// on its children, then recursively on children that got resized.
+// Auto layout parents place their children themselves and are skipped,
+// so are axes whose position or size is not in pixel: percent and auto
+// already follow the parent in the layout pass.
+func (e *Engine) applyConstraints(parent *scene.Node, oldW, oldH float32, edit func(*scene.Node, func())) {
+	if parent.Style == nil || parent.Style.Layout != layout.ModeNone {
+		return
+	}
+
+	_, _, newW, newH := parent.Box()
+	if newW == oldW && newH == oldH {
+		return
+	}
+
+	for _, child := range parent.Children {
+		s := child.Style
+		if s == nil || s.Position != style.PositionRelative {
+			continue
+		}
+
+		x, y, w, h := child.Box()
+		horizontal, vertical := isPixel(s.Left, s.Width), isPixel(s.Top, s.Height)
+
+		newX, newWidth := x, w
+		if horizontal {
+			newX, newWidth = s.Constraints.Horizontal.Apply(x, w, oldW, newW)
+		}
+		newY, newHeight := y, h
+		if vertical {
+			newY, newHeight = s.Constraints.Vertical.Apply(y, h, oldH, newH)
+		}
+		if newX == x && newY == y && newWidth == w && newHeight == h {
+			continue
+		}
+
+		e.resizeNode(child, edit, func() {
+			if horizontal {
+				child.Style.Left, child.Style.Width = layout.Px(newX), layout.Px(newWidth)
+			}
+			if vertical {
+				child.Style.Top, child.Style.Height = layout.Px(newY), layout.Px(newHeight)
+			}
+		})
+	}
+}
+
+func isPixel(dims ...layout.Dimension) bool {
+	for _, d := range dims {
+		if d.Unit != layout.UnitPixel {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+)
+
+const constraintsDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":0,"top":0,"width":200,"height":100},"children":[
+		{"id":3,"type":"POLYGON","style":{"left":10,"top":10,"width":20,"height":20}},
+		{"id":4,"type":"POLYGON","style":{"left":170,"top":10,"width":20,"height":20,"constraints":{"horizontal":1}}},
+		{"id":5,"type":"FRAME","style":{"left":10,"top":40,"width":180,"height":20,"constraints":{"horizontal":2,"vertical":3}},"children":[
+			{"id":6,"type":"POLYGON","style":{"left":170,"top":0,"width":10,"height":10,"constraints":{"horizontal":1}}}
+		]},
+		{"id":7,"type":"POLYGON","style":{"left":100,"top":0,"width":50,"height":50,"constraints":{"horizontal":4,"vertical":4}}},
+		{"id":8,"type":"POLYGON","style":{"left":"10%","top":0,"width":20,"height":20,"constraints":{"horizontal":1}}}
+	]}
+]}}`
+
+func assertStyleBox(t *testing.T, id uint32, x, y, w, h float32) {
+	t.Helper()
+
+	s := engine.findNode(id).Style
+	if s.Left != layout.Px(x) || s.Top != layout.Px(y) || s.Width != layout.Px(w) || s.Height != layout.Px(h) {
+		t.Errorf("Node %d should be at %v %v %v %v, got %v %v %v %v", id, x, y, w, h, s.Left, s.Top, s.Width, s.Height)
+	}
+}
+
+func TestConstraints_SetDimensionProp(t *testing.T) {
+	setupHistory(t)
+	loadDocument(t, []byte(constraintsDocument))
+	engine.reconciler.WorkLoop(0)
+
+	SetDimensionProp(2, PROP_WIDTH, 300, int32(layout.UnitPixel))
+	SetDimensionProp(2, PROP_HEIGHT, 200, int32(layout.UnitPixel))
+
+	assertStyleBox(t, 3, 10, 10, 20, 20)
+	assertStyleBox(t, 4, 270, 10, 20, 20)
+	// Stretched and centered, its own child follows its right edge
+	assertStyleBox(t, 5, 10, 90, 280, 20)
+	assertStyleBox(t, 6, 270, 0, 10, 10)
+	assertStyleBox(t, 7, 150, 0, 75, 100)
+
+	// Percent positions already follow the parent
+	if s := engine.findNode(8).Style; s.Left != layout.Pct(10) {
+		t.Errorf("Percent left should be kept, got %v", s.Left)
+	}
+
+	if engine.findNode(6).Flags&scene.FlagLayoutDirty == 0 {
+		t.Errorf("Constrained nodes must be layout dirty")
+	}
+
+	// One undo step per resize, children included
+	if !Undo() {
+		t.Fatalf("Undo failed")
+	}
+	assertStyleBox(t, 4, 270, 10, 20, 20)
+	assertStyleBox(t, 5, 10, 40, 280, 20)
+
+	if !Undo() {
+		t.Fatalf("Undo failed")
+	}
+	assertStyleBox(t, 4, 170, 10, 20, 20)
+	assertStyleBox(t, 6, 170, 0, 10, 10)
+}
+
+func TestConstraints_FloatSetters(t *testing.T) {
+	now := setupHistory(t)
+	loadDocument(t, []byte(constraintsDocument))
+	engine.reconciler.WorkLoop(0)
+
+	// Same children move as with SetDimensionProp
+	SetFloatProp(2, PROP_WIDTH, 300)
+	assertStyleBox(t, 4, 270, 10, 20, 20)
+	assertStyleBox(t, 6, 270, 0, 10, 10)
+	*now = now.Add(time.Second)
+
+	AllocBatchBuffer(1)
+	copy(engine.batchBuffer, []uint32{2, uint32(PROP_HEIGHT), math.Float32bits(200)})
+	BatchUpdateFloats(1)
+	assertStyleBox(t, 5, 10, 90, 280, 20)
+	assertStyleBox(t, 7, 150, 0, 75, 100)
+
+	// Each setter call is one undo step, children included
+	if !Undo() {
+		t.Fatalf("Undo failed")
+	}
+	assertStyleBox(t, 5, 10, 40, 280, 20)
+	if !Undo() {
+		t.Fatalf("Undo failed")
+	}
+	assertStyleBox(t, 4, 170, 10, 20, 20)
+	assertStyleBox(t, 6, 170, 0, 10, 10)
+}
+
+func TestConstraints_Resize(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(constraintsDocument))
+	frame := engine.findNode(2).Style
+	frame.Constraints.Horizontal = 1
+
+	// The first size is only a reference
+	Resize(800, 600, 1)
+	if frame.Left != layout.Px(0) {
+		t.Fatalf("First resize must not move children, got %v", frame.Left)
+	}
+
+	Resize(1000, 600, 1)
+	if frame.Left != layout.Px(200) {
+		t.Errorf("Frame should follow the right edge of the page, got %v", frame.Left)
+	}
+	// Only moved, its children keep their place
+	assertStyleBox(t, 4, 170, 10, 20, 20)
+
+	if Undo() {
+		t.Errorf("Resize must not be undoable")
+	}
+}
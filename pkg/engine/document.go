@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"unsafe"
+
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+	"engo/pkg/animation"
+	"engo/pkg/fiber"
+	"engo/pkg/scene"
+	"engo/pkg/snapshot"
+)
+
+func (e *Engine) allocJSONBuffer(size int) uintptr {
+	if cap(e.jsonBuffer) < size {
+		e.jsonBuffer = make([]byte, size)
+	}
+	e.jsonBuffer = e.jsonBuffer[:size]
+
+	if size == 0 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(&e.jsonBuffer[0]))
+}
+
+func (e *Engine) exportJSON() uintptr {
+	data, err := scene.MarshalDocument(e.rootNode)
+	if err != nil || len(data) == 0 {
+		e.exportBuffer = e.exportBuffer[:0]
+		return 0
+	}
+
+	// Keep the slice alive until the next export, JS reads it by pointer
+	e.exportBuffer = data
+	return uintptr(unsafe.Pointer(&e.exportBuffer[0]))
+}
+
+func (e *Engine) allocBinaryBuffer(size int) uintptr {
+	if cap(e.binaryBuffer) < size {
+		e.binaryBuffer = make([]uint32, size)
+	}
+	e.binaryBuffer = e.binaryBuffer[:size]
+
+	if size == 0 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(&e.binaryBuffer[0]))
+}
+
+func (e *Engine) allocBatchBuffer(count int) uintptr {
+	size := count * 3
+	if cap(e.batchBuffer) < size {
+		e.batchBuffer = make([]uint32, size)
+	}
+	e.batchBuffer = e.batchBuffer[:size]
+
+	if size == 0 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(&e.batchBuffer[0]))
+}
+
+func (e *Engine) exportBinary() uintptr {
+	words, err := snapshot.Encode(e.rootNode)
+	if err != nil || len(words) == 0 {
+		e.binaryExport = e.binaryExport[:0]
+		return 0
+	}
+
+	e.binaryExport = words
+	return uintptr(unsafe.Pointer(&e.binaryExport[0]))
+}
+
+// loadScene replaces the whole scene graph by root: it resets the
+// selection, rebuilds the spatial index and schedules a full reconcile
+func (e *Engine) loadScene(root *scene.Node) {
+	e.rootNode = root
+	e.setSelection(nil)
+	e.endMarquee()
+
+	// IDs continue after the highest one of the document
+	e.ids.Reset()
+	e.observeIDs(root)
+	e.assignIDs(root)
+
+	e.nodes = make(map[uint32]*scene.Node)
+	e.registerSubtree(root)
+
+	e.history = newHistory()
+	e.transform = transformDrag{}
+	e.guides = nil
+	// Animations target nodes of the old scene
+	e.animations = animation.NewTimeline()
+	e.animated = nil
+	e.flings = make(map[uint32][2]uint32)
+
+	e.spatial = rtree.NewRTreeWithConfig(5, 10)
+	e.indexSubtree(root, protocol.IdentityMatrix())
+
+	// Fresh reconciler, the old fiber tree belongs to the old scene
+	e.reconciler = fiber.NewReconciler(root, e)
+
+	root.MarkDirty(scene.FlagLayoutDirty)
+	e.reconciler.ScheduleUpdate(root)
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"bytes"
+	"testing"
+
+	"engo/internal/algo/rtree"
+)
+
+const testDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":10,"top":10,"width":100,"height":80},"props":{"fill":255},"children":[
+		{"id":3,"type":"TEXT","style":{"left":5,"width":"50%","height":20},"props":{"content":"hi"}}
+	]},
+	{"id":4,"type":"IMAGE","style":{"left":300,"top":300,"width":64,"height":64}}
+]}}`
+
+// loadDocument copies doc into the shared buffer like JS would
+func loadDocument(t testing.TB, doc []byte) {
+	t.Helper()
+
+	engine.allocJSONBuffer(len(doc))
+	copy(engine.jsonBuffer, doc)
+
+	if !InitWithJSON(int32(len(doc))) {
+		t.Fatalf("InitWithJSON rejected document: %s", doc)
+	}
+}
+
+func exportDocument(t *testing.T) []byte {
+	t.Helper()
+
+	if ExportJSON() == 0 {
+		t.Fatalf("ExportJSON failed")
+	}
+
+	return bytes.Clone(engine.exportBuffer[:GetJSONSize()])
+}
+
+func TestInitWithJSON(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(testDocument))
+
+	if engine.rootNode.ID != 1 || len(engine.rootNode.Children) != 2 {
+		t.Fatalf("Scene graph not rebuilt")
+	}
+
+	// Text is at (10+5, 10) in world space
+	hits := engine.spatial.Search(rtree.Rect{MinX: 14, MinY: 9, MaxX: 16, MaxY: 11})
+	if len(hits) != 2 {
+		t.Errorf("Expected frame and text in spatial index, got %v", hits)
+	}
+
+	if engine.reconciler.WorkLoop(0) {
+		t.Fatalf("Reconcile should finish without budget")
+	}
+
+	frameFiber := engine.reconciler.CurrentRoot.Child
+	if frameFiber == nil || frameFiber.Node.ID != 2 || frameFiber.Child.Node.ID != 3 {
+		t.Fatalf("Fiber tree doesn't mirror the document")
+	}
+
+	if InitWithJSON(int32(len(engine.jsonBuffer) + 1)) {
+		t.Errorf("Size larger than the buffer should be rejected")
+	}
+}
+
+func TestExportJSON_RoundTrip(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(testDocument))
+
+	first := exportDocument(t)
+	loadDocument(t, first)
+	second := exportDocument(t)
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("Export is not stable\nfirst:  %s\nsecond: %s", first, second)
+	}
+}
+
+func TestExportBinary_RoundTrip(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(testDocument))
+	jsonBefore := exportDocument(t)
+
+	if ExportBinary() == 0 {
+		t.Fatalf("ExportBinary failed")
+	}
+	words := append([]uint32{}, engine.binaryExport[:GetBinarySize()]...)
+
+	Init()
+	engine.allocBinaryBuffer(len(words))
+	copy(engine.binaryBuffer, words)
+	if !InitWithBinary(int32(len(words))) {
+		t.Fatalf("InitWithBinary rejected snapshot")
+	}
+
+	if jsonAfter := exportDocument(t); !bytes.Equal(jsonBefore, jsonAfter) {
+		t.Errorf("Binary round trip changed the scene\nbefore: %s\nafter:  %s", jsonBefore, jsonAfter)
+	}
+}
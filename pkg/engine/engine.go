@@ -1,23 +1,74 @@
 package engine
 
 import (
+	"math"
+
 	"engo/internal/algo/rtree"
 	"engo/internal/protocol"
+	"engo/pkg/animation"
 	"engo/pkg/fiber"
 	"engo/pkg/layout"
 	"engo/pkg/scene"
+	"engo/pkg/snapshot"
+	"engo/pkg/style"
+	"engo/pkg/text"
 )
 
-var id = 0
-
 type Engine struct {
 	commandBuffer *protocol.CommandBuffer
-	// viewport      *Viewport
+	// Camera of the canvas, world to screen
+	viewport *Viewport
 	spatial  *rtree.RTree
+	// Nodes found on screen by the last cull, see cull
+	visible  map[*scene.Node]uint8
 	rootNode *scene.Node
 	// current selected node, rootNode if nil
-	selection  []*scene.Node
-	reconciler *fiber.Reconciler
+	selection []*scene.Node
+	// Bumped whenever the selection changes, JS polls it
+	selectionVersion uint32
+	// IDs of selection, read by JS
+	selectionIDs []uint32
+	reconciler   *fiber.Reconciler
+
+	ids   scene.IDAllocator
+	nodes map[uint32]*scene.Node
+
+	// Shared memory JS writes documents into before InitWithJSON
+	jsonBuffer []byte
+	// Last document serialized by ExportJSON, read by JS
+	exportBuffer []byte
+	// Same as above for binary snapshots, in uint32 words
+	binaryBuffer []uint32
+	binaryExport []uint32
+	// [NodeID, PropID, Value] triples of BatchUpdateFloats
+	batchBuffer []uint32
+	// Metrics of RegisterFont
+	fontBuffer []uint32
+	// Keyframes of AnimateKeyframes, keyframeWords per entry
+	keyframeBuffer []uint32
+
+	// Fonts outlive documents, they are kept across loadScene
+	fonts *text.Registry
+
+	// Shared memory JS writes pointer and keys into, and what
+	// Update read from it this frame
+	inputState *protocol.InputState
+	input      protocol.Input
+	// Rubber-band selection being dragged, if any
+	marquee marquee
+	// Canvas being dragged with space or the middle button
+	pan panDrag
+	// Move, resize or rotate of the selection being dragged, if any
+	transform transformDrag
+	// Smart guides of that drag, drawn over the selection
+	guides []guide
+
+	history    *history
+	animations *animation.Timeline
+	// Nodes animated while a pass was running, marked again after it
+	animated []animatedNode
+	// Momentum animations (X, Y) of scroll containers being flung
+	flings map[uint32][2]uint32
 }
 
 var engine *Engine
@@ -28,37 +79,201 @@ func Init() {
 
 	engine = &Engine{
 		commandBuffer: protocol.NewCommandBuffer(),
-		// viewport:      NewViewport(),
-		spatial:    rtree.NewRTreeWithConfig(5, 10),
-		reconciler: fiber.NewReconciler(rootNode, nil),
+		viewport:      NewViewport(),
+		fonts:         text.NewRegistry(),
+		inputState:    protocol.NewInputState(),
 	}
 
-	engine.reconciler.ScheduleUpdate(rootNode)
+	engine.loadScene(rootNode)
 }
 
-// TODO: Implement this, really important
-func InitWithJSON() {
+// Cấp phát vùng nhớ để JS ghi JSON document vào
+// Trả về pointer tới vùng nhớ có độ dài size bytes
+//
+//go:export
+func AllocJSONBuffer(size int32) uintptr {
+	return engine.allocJSONBuffer(int(size))
+}
+
+// Nạp toàn bộ document (JSON) đã được JS ghi vào buffer của AllocJSONBuffer
+// Schema xem ở scene.UnmarshalDocument
+// Trả về false nếu document không hợp lệ, scene hiện tại được giữ nguyên
+//
+//go:export
+func InitWithJSON(size int32) bool {
+	if int(size) > len(engine.jsonBuffer) {
+		return false
+	}
+
+	root, err := scene.UnmarshalDocument(engine.jsonBuffer[:size])
+	if err != nil {
+		return false
+	}
 
+	engine.loadScene(root)
+	return true
 }
 
+// Cấp phát vùng nhớ (số lượng phần tử uint32) để JS ghi binary snapshot vào
+//
 //go:export
-func Resize(width, height, dpr float32) {
+func AllocBinaryBuffer(size int32) uintptr {
+	return engine.allocBinaryBuffer(int(size))
+}
 
+// Giống InitWithJSON nhưng đọc binary snapshot (xem package snapshot)
+// size: số lượng phần tử uint32 JS đã ghi
+//
+//go:export
+func InitWithBinary(size int32) bool {
+	if int(size) > len(engine.binaryBuffer) {
+		return false
+	}
+
+	root, err := snapshot.Decode(engine.binaryBuffer[:size])
+	if err != nil {
+		return false
+	}
+
+	engine.loadScene(root)
+	return true
+}
+
+// Kích thước vùng hiển thị (CSS pixel) thay đổi, page nhận kích thước mới
+// Con của page đi theo constraints, thay đổi này không vào history
+// Viewport dùng kích thước này cho zoom to fit và SetZoom
+//
+//go:export
+func Resize(width, height, dpr float32) {
+	engine.resize(width, height)
 }
 
 // Lấy địa chỉ con trỏ của Shared Memory (Input State)
-// Để JS biết chỗ mà ghi tọa độ chuột vào
+// Để JS biết chỗ mà ghi tọa độ chuột vào, layout xem ở protocol.InputState
+// JS kiểm tra phần tử đầu tiên (version) trước khi ghi
 //
-// export
+//go:export
 func GetInputStatePtr() uintptr {
-
+	return uintptr(engine.inputState.GetPtr())
 }
 
 // Chạy logic tính toán (Layout, Physics, Animation)
 // dt: Delta time (giây)
+// Sau mỗi lần gọi Command Buffer chứa frame mới
+//
+//go:export
+func Update(dt float32) {
+	engine.update(dt)
+}
+
+// Chạy animation cho thuộc tính propCode (X, Y, W, H, Opacity, Radius, Fill, Stroke)
+// từ giá trị hiện tại tới to
+// to: bit của float32, hoặc màu RGBA với PROP_FILL/PROP_STROKE
+// duration, delay: giây; easing: EASE_LINEAR, EASE, EASE_IN, EASE_OUT, EASE_IN_OUT
+// Trả về ID của animation, 0 nếu không tìm thấy node hoặc thuộc tính không animate được
+//
+//go:export
+func Animate(nodeID uint32, propCode int32, to uint32, duration, delay float32, easing int32) uint32 {
+	node := engine.findNode(nodeID)
+	if node == nil {
+		return 0
+	}
+
+	from, kind, ok := animatedProp(node, propCode)
+	if !ok {
+		return 0
+	}
+
+	target := float64(math.Float32frombits(to))
+	if kind == animation.KindColor {
+		target = float64(to)
+	}
+
+	return engine.animate(node, propCode, &animation.Animation{
+		Keyframes: []animation.Keyframe{
+			{Offset: 0, Value: from, Easing: easingOf(easing)},
+			{Offset: 1, Value: target},
+		},
+		Duration: duration,
+		Delay:    delay,
+	})
+}
+
+// Chạy animation lò xo cho thuộc tính propCode từ giá trị hiện tại tới to (như Animate)
+// stiffness, damping, mass: thông số lò xo, mass = 0 là 1
+// velocity: vận tốc ban đầu (đơn vị/giây), bỏ qua với màu
+// Trả về ID của animation, 0 nếu không tìm thấy node hoặc thuộc tính không animate được
+//
+//go:export
+func AnimateSpring(nodeID uint32, propCode int32, to uint32, stiffness, damping, mass, velocity float32) uint32 {
+	node := engine.findNode(nodeID)
+	if node == nil {
+		return 0
+	}
+
+	_, kind, ok := animatedProp(node, propCode)
+	if !ok {
+		return 0
+	}
+
+	target := float64(math.Float32frombits(to))
+	if kind == animation.KindColor {
+		target = float64(to)
+	}
+
+	return engine.animateSpring(node, propCode, &animation.SpringAnimation{
+		Spring: animation.Spring{
+			Stiffness: float64(stiffness),
+			Damping:   float64(damping),
+			Mass:      float64(mass),
+		},
+		To:       target,
+		Velocity: float64(velocity),
+	})
+}
+
+// Cấp phát vùng nhớ cho count keyframe của AnimateKeyframes
+// Mỗi keyframe 7 phần tử uint32: [offset, value, easing, x1, y1, x2, y2]
+// offset, x1..y2 là bit của float32; value như tham số to của Animate
+// x1, y1, x2, y2 chỉ dùng khi easing là EASE_CUBIC_BEZIER
+//
+//go:export
+func AllocKeyframeBuffer(count int32) uintptr {
+	return engine.allocKeyframeBuffer(int(count))
+}
+
+// Chạy count keyframe JS đã ghi vào buffer của AllocKeyframeBuffer
+// iterations: số lần lặp, -1 là lặp mãi; alternate: lần lặp lẻ chạy ngược
+// Trả về ID của animation, 0 nếu không tìm thấy node hoặc thuộc tính không animate được
+//
+//go:export
+func AnimateKeyframes(nodeID uint32, propCode int32, count int32, duration, delay float32, iterations int32, alternate bool) uint32 {
+	node := engine.findNode(nodeID)
+	if node == nil || count <= 0 || int(count)*keyframeWords > len(engine.keyframeBuffer) {
+		return 0
+	}
+
+	_, kind, ok := animatedProp(node, propCode)
+	if !ok {
+		return 0
+	}
+
+	return engine.animate(node, propCode, &animation.Animation{
+		Keyframes:  readKeyframes(engine.keyframeBuffer[:int(count)*keyframeWords], kind),
+		Duration:   duration,
+		Delay:      delay,
+		Iterations: int(iterations),
+		Alternate:  alternate,
+	})
+}
+
+// Dừng animation, thuộc tính giữ giá trị hiện tại
+// Trả về false nếu animation đã kết thúc hoặc không tồn tại
 //
-// export
-func Update(dt float32)
+//go:export
+func CancelAnimation(id uint32) bool {
+	return engine.animations.Cancel(id)
+}
 
 // Lấy địa chỉ bắt đầu của Command Buffer (Mảng uint32 chứa OpCode)
 //
@@ -68,49 +283,236 @@ func GetRenderBufferPtr() uintptr {
 }
 
 // Lấy kích thước hiện tại của Buffer (số lượng phần tử uint32)
+//
+//go:export
 func GetRenderBufferSize() int32 {
 	return int32(engine.commandBuffer.GetSize())
 }
 
-// Xử lý Click/Mousedown/Up
+// Xử lý Click/Mousedown/Up, vị trí con trỏ đọc từ Input State
 // button: 0 (Left), 1 (Middle), 2 (Right)
 // action: 0 (Down), 1 (Up)
-// modifiers: Bitmask (Ctrl, Shift, Alt)
-func OnMouseAction(button int32, action int32, modifiers int32)
+// modifiers: Bitmask protocol.Mod* (Shift, Ctrl, Alt, Meta)
+//
+//go:export
+func OnMouseAction(button int32, action int32, modifiers int32) {
+	engine.mouseAction(button, action, uint32(modifiers))
+}
+
+// Chọn node theo id
+// mode: SELECT_REPLACE (chỉ chọn node này), SELECT_ADD, SELECT_REMOVE, SELECT_TOGGLE
+// Trả về false nếu không tìm thấy node
+//
+//go:export
+func SelectNode(id uint32, mode int32) bool {
+	node := engine.findNode(id)
+	if node == nil || node == engine.rootNode {
+		return false
+	}
+
+	engine.selectNode(node, mode)
+	return true
+}
+
+// Bỏ chọn tất cả
+//
+//go:export
+func ClearSelection() {
+	engine.setSelection(nil)
+}
+
+// Chọn tất cả node cùng cha với selection (Ctrl+A), các node trên Page nếu chưa chọn gì
+//
+//go:export
+func SelectAll() {
+	engine.selectAll()
+}
+
+// Chọn node cha của các node đang chọn (Shift+Enter)
+//
+//go:export
+func SelectParent() {
+	engine.selectParent()
+}
+
+// Chọn các node con của các node đang chọn (Enter)
+//
+//go:export
+func SelectChildren() {
+	engine.selectChildren()
+}
+
+// Chọn node kế tiếp cùng cha (Tab), node phía trước nếu reverse (Shift+Tab)
+//
+//go:export
+func SelectSibling(reverse bool) {
+	engine.selectSibling(reverse)
+}
+
+// Phiên bản của selection, tăng mỗi khi selection thay đổi
+// JS so với giá trị lần trước để biết khi nào cần đọc lại selection
+//
+//go:export
+func GetSelectionVersion() uint32 {
+	return engine.selectionVersion
+}
+
+// Địa chỉ mảng ID (uint32) các node đang chọn, theo thứ tự chọn
+// Chỉ hợp lệ tới khi selection thay đổi
+//
+//go:export
+func GetSelectionPtr() uintptr {
+	return engine.selectionPtr()
+}
+
+// Số lượng node đang chọn
+//
+//go:export
+func GetSelectionSize() int32 {
+	return int32(len(engine.selectionIDs))
+}
+
+// Tìm node trên cùng tại điểm (x, y) trên màn hình (CSS pixel)
+// Như click trong Figma: trúng con của Group/Frame ngoài phạm vi đang chọn
+// thì trả về Group/Frame đó
+// Node ẩn, bị khóa hoặc bị cắt bởi Frame cha (Overflow) không được tính
+// Trả về 0 nếu không trúng node nào
+//
+//go:export
+func HitTest(x, y float32) uint32 {
+	wx, wy := engine.screenToWorld(x, y)
+	if node := engine.hitTest(wx, wy, false); node != nil {
+		return node.ID
+	}
+	return 0
+}
+
+// Giống HitTest nhưng trả về node sâu nhất (Ctrl/Cmd + click)
+//
+//go:export
+func HitTestDeep(x, y float32) uint32 {
+	wx, wy := engine.screenToWorld(x, y)
+	if node := engine.hitTest(wx, wy, true); node != nil {
+		return node.ID
+	}
+	return 0
+}
 
 // Xử lý bàn phím
 // key_code: Mã ASCII hoặc KeyCode của JS
-func OnKeyAction(keyCode int32, action int32, modifiers int32)
+//
+//go:export
+func OnKeyAction(keyCode int32, action int32, modifiers int32) {
+	engine.keyAction(uint32(keyCode), uint32(action))
+}
 
 // Xử lý Zoom/Pan (nếu không dùng Shared Memory cho cái này)
-func OnWheel(deltaX, deltaY float32, isZoom bool)
+// isZoom: Ctrl/Cmd + wheel hoặc pinch trên trackpad, zoom quanh con trỏ
+//
+//go:export
+func OnWheel(deltaX, deltaY float32, isZoom bool) {
+	engine.wheel(deltaX, deltaY, isZoom)
+}
 
-func CreateNode(nodeType scene.NodeType, budgetMs int64) uint32 {
-	parentNode := engine.GetInsertTarget()
+// Pinch zoom (touch/Safari gesture)
+// factor: tỉ lệ so với lần gọi trước, zoom quanh điểm (x, y) trên màn hình
+// factor NaN, vô hạn hoặc <= 0 bị bỏ qua
+//
+//go:export
+func OnPinch(factor, x, y float32) {
+	engine.viewport.ZoomBy(factor, x, y)
+}
+
+// Đặt mức zoom quanh tâm canvas (1 = 100%), bị giới hạn trong [MinScale, MaxScale]
+// scale NaN, vô hạn hoặc <= 0 bị bỏ qua
+//
+//go:export
+func SetZoom(scale float32) {
+	v := engine.viewport
+	v.ZoomAt(scale, v.Width/2, v.Height/2)
+}
+
+//go:export
+func GetZoom() float32 {
+	return engine.viewport.Scale
+}
 
-	parentNode.MarkDirty()
+// Zoom để thấy toàn bộ page
+// Trả về false nếu page trống
+//
+//go:export
+func ZoomToFit() bool {
+	return engine.zoomToFit()
+}
+
+// Zoom để thấy các node đang chọn
+// Trả về false nếu không chọn gì
+//
+//go:export
+func ZoomToSelection() bool {
+	return engine.zoomToSelection()
+}
 
-	// Add node to scene graph
-	node := scene.NewNode(nodeType, parentNode)
+// Tạo node mới trong node đang chọn (hoặc page), node mới được chọn
+// nodeType: scene.NodeType; budgetMs: thời gian (ms) được reconcile ngay, 0 là không giới hạn
+// Trả về ID của node, 0 nếu nodeType không hợp lệ
+//
+//go:export
+func CreateNode(nodeType int32, budgetMs int32) uint32 {
+	t := scene.NodeType(nodeType)
+	if !t.IsValid() {
+		return 0
+	}
 
-	// Set current fiber root to parentNode
-	engine.reconciler.ScheduleUpdate(parentNode)
+	parentNode := engine.GetInsertTarget()
 
-	hasMoreWork := engine.reconciler.WorkLoop(budgetMs)
+	node := scene.NewNode(t, parentNode)
+	node.ID = engine.ids.Next()
+	node.Style = style.NewStyle()
+	node.Props = scene.NewProps(t)
 
-	if hasMoreWork {
+	// Add node to scene graph, marks parentNode dirty
+	// and schedules the reconciler
+	engine.addNode(parentNode, node, len(parentNode.Children))
 
-	} else {
+	engine.SetSelection(node, true)
 
+	// Drawn right away if it fits the budget, Update finishes it otherwise
+	if !engine.reconciler.WorkLoop(int64(budgetMs)) {
+		engine.render()
 	}
 
-	engine.commandBuffer.WriteEof()
+	return node.ID
+}
+
+// Xóa Node (không xóa được Page)
+//
+//go:export
+func RemoveNode(id uint32) {
+	node := engine.findNode(id)
+	if node == nil || node == engine.rootNode {
+		return
+	}
 
-	engine.SetSelection(node, false)
+	engine.deleteNode(node)
 }
 
-// Xóa Node
-func RemoveNode(id uint32)
+// Nhân bản node (cùng toàn bộ node con) ngay sau node gốc
+// Trả về ID của bản sao, 0 nếu không tìm thấy node
+//
+//go:export
+func DuplicateNode(id uint32) uint32 {
+	node := engine.findNode(id)
+	if node == nil || node.Parent == nil {
+		return 0
+	}
+
+	clone := node.Clone(&engine.ids)
+	engine.addNode(node.Parent, clone, indexOf(node.Parent, node)+1)
+	engine.SetSelection(clone, true)
+
+	return clone.ID
+}
 
 func UpdateNodeType(id uint32) {
 
@@ -120,50 +522,203 @@ func UpdateNodeType(id uint32) {
 
 // Set thuộc tính số thực (X, Y, W, H, Opacity, Radius...)
 // propCode: Enum (1=X, 2=Y, 3=W, 4=H...)
-func SetFloatProp(id uint32, propCode int32, value float32)
+// Đổi W/H thì node con đi theo constraints như SetDimensionProp
+//
+//go:export
+func SetFloatProp(id uint32, propCode int32, value float32) {
+	node := engine.findNode(id)
+	if node == nil {
+		return
+	}
+
+	engine.editSize(node, propCode, func() {
+		setFloatProp(node, propCode, value)
+	})
+}
+
+// Cuộn nội dung của một Frame có Overflow = Scroll (prototype, không vào history)
+// x, y: scroll offset tính bằng pixel, node sticky/fixed bên trong được layout lại
+//
+//go:export
+func SetScrollOffset(id uint32, x, y float32) {
+	node := engine.findNode(id)
+	if node == nil || !node.IsScrollContainer() {
+		return
+	}
+
+	// Người dùng cuộn tay thì dừng quán tính
+	engine.stopFling(node)
+	engine.scrollNode(node, x, y)
+}
+
+// Thả tay khi đang cuộn, nội dung tiếp tục trôi theo quán tính (chạy trong Update)
+// và bật lại nếu vượt quá mép nội dung
+// vx, vy: vận tốc lúc thả tay (pixel/giây)
+//
+//go:export
+func ScrollFling(id uint32, vx, vy float32) {
+	node := engine.findNode(id)
+	if node == nil || !node.IsScrollContainer() {
+		return
+	}
+
+	engine.fling(node, vx, vy)
+}
 
 // Set thuộc tính số nguyên (Color, Visibility, Z-Index)
 // value: Chứa cả màu RGBA nén lại hoặc Enum ID
-func SetIntProp(id uint32, propCode int32, value int32)
+func SetIntProp(id uint32, propCode int32, value int32) {}
 
 // Set chuỗi (Text Content, Name)
 // Vì truyền string qua Wasm tốn kém, ta truyền ptr và length
-func SetStringProp(id uint32, propCode int32, strPtr uint32, len int32)
+func SetStringProp(id uint32, propCode int32, strPtr uint32, len int32) {}
 
 // Báo cho Go biết đã load xong ảnh
 // JS gửi kích thước thật để Go tính layout
-func RegisterImage(imgID uint32, width float32, height float32)
+func RegisterImage(imgID uint32, width float32, height float32) {}
+
+// Cấp phát vùng nhớ (số lượng phần tử uint32) để JS ghi font metrics vào
+//
+//go:export
+func AllocFontBuffer(size int32) uintptr {
+	return engine.allocFontBuffer(int(size))
+}
 
 // Đăng ký Font metrics (để Go tính đo độ rộng chữ)
-// Go cần biết Ascent, Descent, AdvanceWidth trung bình...
-func RegisterFont(fontID uint32, ptrMetrics uint32)
+// JS ghi metrics (Ascent, Descent, Advance từng glyph, Kerning...) vào buffer
+// của AllocFontBuffer, format xem ở text.ParseFont
+// size: số lượng phần tử uint32 JS đã ghi
+// Trả về false nếu metrics không hợp lệ, text node được layout lại nếu thành công
+//
+//go:export
+func RegisterFont(fontID uint32, size int32) bool {
+	if int(size) > len(engine.fontBuffer) {
+		return false
+	}
+
+	return engine.registerFont(fontID, engine.fontBuffer[:size])
+}
 
 // Lấy cây Scene Graph dạng JSON (để save file hoặc hiện Layer Tree bên React)
-// Hàm này trả về pointer tới vùng nhớ chứa chuỗi JSON
-func ExportJSON() uintptr
+// Hàm này trả về pointer tới vùng nhớ chứa chuỗi JSON, 0 nếu lỗi
+// Vùng nhớ hợp lệ tới lần gọi ExportJSON kế tiếp
+//
+//go:export
+func ExportJSON() uintptr {
+	return engine.exportJSON()
+}
+
+// Lấy độ dài chuỗi JSON (bytes) của lần ExportJSON gần nhất
+//
+//go:export
+func GetJSONSize() int32 {
+	return int32(len(engine.exportBuffer))
+}
+
+// Giống ExportJSON nhưng xuất binary snapshot, nhỏ và nhanh hơn cho file lớn
+//
+//go:export
+func ExportBinary() uintptr {
+	return engine.exportBinary()
+}
+
+// Lấy kích thước (số lượng phần tử uint32) của lần ExportBinary gần nhất
+//
+//go:export
+func GetBinarySize() int32 {
+	return int32(len(engine.binaryExport))
+}
 
-// Lấy độ dài chuỗi JSON
-func GetJSONSize() int32
+// Cấp phát vùng nhớ cho count thay đổi của BatchUpdateFloats
+//
+//go:export
+func AllocBatchBuffer(count int32) uintptr {
+	return engine.allocBatchBuffer(int(count))
+}
 
 // Nhận một mảng các thay đổi: [NodeID, PropID, Value, NodeID, PropID, Value...]
-func BatchUpdateFloats(ptr uint32, count int32)
+// JS ghi vào vùng nhớ của AllocBatchBuffer, Value là bit của float32
+// Cả batch là một bước Undo
+//
+//go:export
+func BatchUpdateFloats(count int32) {
+	engine.history.begin()
+
+	for i := 0; i < int(count) && i*3+2 < len(engine.batchBuffer); i++ {
+		entry := engine.batchBuffer[i*3 : i*3+3]
+
+		node := engine.findNode(entry[0])
+		if node == nil {
+			continue
+		}
+
+		propCode := int32(entry[1])
+		engine.editSize(node, propCode, func() {
+			setFloatProp(node, propCode, math.Float32frombits(entry[2]))
+		})
+	}
+
+	engine.history.commit()
+}
+
+// Hoàn tác bước gần nhất, trả về false nếu không còn gì để hoàn tác
+//
+//go:export
+func Undo() bool {
+	return engine.undo()
+}
+
+//go:export
+func Redo() bool {
+	return engine.redo()
+}
+
+// Gom tất cả thay đổi tới CommitTransaction thành một bước Undo
+// (ví dụ: kéo thả node). Có thể lồng nhau.
+//
+//go:export
+func BeginTransaction() {
+	engine.history.begin()
+}
+
+//go:export
+func CommitTransaction() {
+	engine.history.commit()
+}
 
 //go:export setDimensionProp
 func SetDimensionProp(nodeID uint32, propID int32, value float32, unit int32) {
-	// Maybe page node
-	node := engine.selection[0]
+	node := engine.findNode(nodeID)
+	if node == nil {
+		return
+	}
 
 	dim := layout.Dimension{
 		Value: value,
 		Unit:  layout.Unit(unit),
 	}
 
-	switch propID {
-	case PROP_WIDTH:
-		node.Style.Width = dim
-	case PROP_HEIGHT:
-		node.Style.Height = dim
+	edit := func() {
+		switch propID {
+		case PROP_X:
+			node.Style.Left = dim
+		case PROP_Y:
+			node.Style.Top = dim
+		case PROP_WIDTH:
+			node.Style.Width = dim
+		case PROP_HEIGHT:
+			node.Style.Height = dim
+		case PROP_MIN_WIDTH:
+			node.Style.MinWidth = dim
+		case PROP_MAX_WIDTH:
+			node.Style.MaxWidth = dim
+		case PROP_MIN_HEIGHT:
+			node.Style.MinHeight = dim
+		case PROP_MAX_HEIGHT:
+			node.Style.MaxHeight = dim
+		}
 	}
 
-	node.MarkDirty()
+	// Percentages and auto are kept, the layout pass resolves them
+	engine.editSize(node, propID, edit)
 }
@@ -23,11 +23,122 @@ func (e *Engine) GetInsertTarget() *scene.Node {
 	return e.rootNode
 }
 
-func (e *Engine) SetSelection(n *scene.Node, replace bool) {
-	if replace {
-		e.selection = append(e.selection, n)
+// scrollNode moves the children of a scroll container, sticky and
+// fixed descendants are placed again by the next layout pass
+func (e *Engine) scrollNode(node *scene.Node, x, y float32) {
+	e.setScroll(node, x, y)
+	node.MarkDirty(scene.FlagLayoutDirty)
+	e.reconciler.ScheduleUpdate(e.rootNode)
+}
+
+// setScroll moves the children of node and their world bounds, the
+// caller schedules the update
+func (e *Engine) setScroll(node *scene.Node, x, y float32) {
+	node.ScrollX, node.ScrollY = x, y
+
+	for _, child := range node.Children {
+		e.reindexSubtree(child)
+	}
+}
+
+// findNode looks up node by ID in the scene graph, nil if not found
+// or if the node has been removed
+func (e *Engine) findNode(id uint32) *scene.Node {
+	return e.nodes[id]
+}
+
+// registerSubtree adds node and its descendants to the ID index
+func (e *Engine) registerSubtree(node *scene.Node) {
+	e.nodes[node.ID] = node
+
+	for _, child := range node.Children {
+		e.registerSubtree(child)
+	}
+}
+
+func (e *Engine) unregisterSubtree(node *scene.Node) {
+	delete(e.nodes, node.ID)
+
+	for _, child := range node.Children {
+		e.unregisterSubtree(child)
+	}
+}
+
+// assignIDs gives an ID to nodes of the subtree that don't have one
+// yet, after all existing IDs have been observed by the allocator
+func (e *Engine) assignIDs(node *scene.Node) {
+	if node.ID == 0 {
+		node.ID = e.ids.Next()
+	}
+
+	for _, child := range node.Children {
+		e.assignIDs(child)
+	}
+}
+
+func (e *Engine) observeIDs(node *scene.Node) {
+	e.ids.Observe(node.ID)
+
+	for _, child := range node.Children {
+		e.observeIDs(child)
+	}
+}
+
+// addNode inserts node into parent at index as an undoable step
+func (e *Engine) addNode(parent, node *scene.Node, index int) {
+	e.insertChild(parent, node, index)
+	e.history.record(&treeCommand{parent: parent, node: node, index: index, insert: true})
+}
+
+// deleteNode detaches node from its parent as an undoable step
+func (e *Engine) deleteNode(node *scene.Node) {
+	parent := node.Parent
+	if parent == nil {
 		return
 	}
 
-	e.selection = []*scene.Node{n}
+	index := indexOf(parent, node)
+	e.removeChild(node)
+	e.history.record(&treeCommand{parent: parent, node: node, index: index, insert: false})
+}
+
+func (e *Engine) insertChild(parent, node *scene.Node, index int) {
+	if index < len(parent.Children) {
+		parent.InsertBefore(node, parent.Children[index])
+	} else {
+		parent.AppendChild(node)
+	}
+
+	e.registerSubtree(node)
+	e.indexSubtree(node, worldMatrix(parent))
+
+	parent.MarkDirty(scene.FlagLayoutDirty)
+	e.reconciler.ScheduleUpdate(e.rootNode)
+}
+
+func (e *Engine) removeChild(node *scene.Node) {
+	parent := node.Parent
+	if parent == nil {
+		return
+	}
+
+	// ID stays reserved, undo may bring node back
+	e.unregisterSubtree(node)
+	e.removeSubtreeSpatial(node)
+	parent.RemoveChild(node)
+
+	// Removed nodes can't stay selected
+	e.setSelection(e.selection)
+
+	parent.MarkDirty(scene.FlagLayoutDirty)
+	e.reconciler.ScheduleUpdate(e.rootNode)
+}
+
+func indexOf(parent, child *scene.Node) int {
+	for i, c := range parent.Children {
+		if c == child {
+			return i
+		}
+	}
+	return -1
 }
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"testing"
+
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+)
+
+func TestIDs_Init(t *testing.T) {
+	Init()
+
+	if engine.rootNode.ID == 0 || engine.findNode(engine.rootNode.ID) != engine.rootNode {
+		t.Fatalf("Page should get an ID and be indexed")
+	}
+
+	seen := map[uint32]bool{engine.rootNode.ID: true}
+	for range 20 {
+		id := CreateNode(int32(scene.Polygon), 0)
+		if id == 0 || seen[id] {
+			t.Fatalf("CreateNode returned duplicated id %d", id)
+		}
+		seen[id] = true
+
+		if engine.findNode(id) == nil {
+			t.Errorf("Node %d not indexed", id)
+		}
+	}
+}
+
+func TestIDs_AfterInitWithJSON(t *testing.T) {
+	Init()
+	CreateNode(int32(scene.Frame), 0)
+	loadDocument(t, []byte(testDocument))
+
+	// Highest ID in testDocument is 4
+	if id := CreateNode(int32(scene.Frame), 0); id != 5 {
+		t.Errorf("Expected next id 5 after load, got %d", id)
+	}
+
+	// Reloading the same document gives the same IDs again
+	loadDocument(t, []byte(testDocument))
+	if id := CreateNode(int32(scene.Frame), 0); id != 5 {
+		t.Errorf("Allocation should be deterministic, got %d", id)
+	}
+}
+
+func TestIDs_RemoveAndDuplicate(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(testDocument))
+
+	cloneID := DuplicateNode(2)
+	clone := engine.findNode(cloneID)
+	if clone == nil || clone.Parent != engine.rootNode || engine.rootNode.Children[1] != clone {
+		t.Fatalf("Duplicate should be placed right after the original")
+	}
+
+	textClone := clone.Children[0]
+	if textClone.ID == 3 || engine.findNode(textClone.ID) != textClone {
+		t.Errorf("Cloned children need their own indexed IDs, got %d", textClone.ID)
+	}
+	if textClone.Props.(*scene.TextProps) == engine.findNode(3).Props {
+		t.Errorf("Clone shares props with the original")
+	}
+
+	RemoveNode(2)
+	if engine.findNode(2) != nil || engine.findNode(3) != nil {
+		t.Errorf("Removed subtree still addressable")
+	}
+
+	Undo()
+	if engine.findNode(2) == nil || engine.findNode(3) == nil {
+		t.Errorf("Undo should make the subtree addressable again")
+	}
+
+	SetDimensionProp(3, PROP_WIDTH, 25, 1)
+	text := engine.findNode(3)
+	if text.Style.Width != layout.Pct(25) {
+		t.Errorf("SetDimensionProp should address node 3 and keep the unit, got %v", text.Style.Width)
+	}
+	if _, _, w, _ := text.Box(); w != 25 {
+		t.Errorf("25%% of 100 should be 25, got %v", w)
+	}
+}
@@ -0,0 +1,304 @@
+package engine
+
+import (
+	"time"
+
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+// Property edits on the same node closer than this are merged
+// into one undo step (typing a value, scrubbing a slider...)
+const coalesceWindow = 500 * time.Millisecond
+
+// Max number of undo steps kept in memory
+const historyLimit = 200
+
+// command is one recorded mutation that knows how to revert itself
+type command interface {
+	undo(e *Engine)
+	redo(e *Engine)
+}
+
+// editCommand stores style and props of a node before and after an edit
+type editCommand struct {
+	node *scene.Node
+
+	beforeStyle, afterStyle style.Style
+	beforeProps, afterProps scene.Props
+}
+
+func (c *editCommand) undo(e *Engine) {
+	c.apply(e, c.beforeStyle, c.beforeProps)
+}
+
+func (c *editCommand) redo(e *Engine) {
+	c.apply(e, c.afterStyle, c.afterProps)
+}
+
+func (c *editCommand) apply(e *Engine, s style.Style, props scene.Props) {
+	*c.node.Style = cloneStyle(s)
+	if props != nil {
+		c.node.Props = props.Clone()
+	}
+
+	e.afterEdit(c.node)
+}
+
+// treeCommand inserts (or removes) node at index of parent
+type treeCommand struct {
+	parent *scene.Node
+	node   *scene.Node
+	index  int
+	insert bool
+}
+
+func (c *treeCommand) undo(e *Engine) {
+	c.apply(e, !c.insert)
+}
+
+func (c *treeCommand) redo(e *Engine) {
+	c.apply(e, c.insert)
+}
+
+func (c *treeCommand) apply(e *Engine, insert bool) {
+	if insert {
+		e.insertChild(c.parent, c.node, c.index)
+	} else {
+		e.removeChild(c.node)
+	}
+}
+
+// transaction is one undo step
+type transaction struct {
+	commands []command
+	// Last time a command was merged into it, for coalescing
+	touched time.Time
+}
+
+type history struct {
+	undoStack []*transaction
+	redoStack []*transaction
+
+	// Open transaction of BeginTransaction, nil if none
+	current *transaction
+	depth   int
+
+	// Last single edit step, later edits of the same node within
+	// coalesceWindow merge into it. Cleared by commit, undo and redo.
+	coalescing *transaction
+
+	now func() time.Time
+}
+
+func newHistory() *history {
+	return &history{now: time.Now}
+}
+
+func (h *history) begin() {
+	if h.depth == 0 {
+		h.current = &transaction{}
+		h.coalescing = nil
+	}
+	h.depth++
+}
+
+// commit closes the transaction, the outermost commit pushes it
+// as one undo step. Returns false if there was nothing to commit.
+func (h *history) commit() bool {
+	if h.depth == 0 {
+		return false
+	}
+
+	h.depth--
+	if h.depth > 0 {
+		return true
+	}
+
+	tx := h.current
+	h.current = nil
+	if len(tx.commands) == 0 {
+		return false
+	}
+
+	tx.touched = h.now()
+	h.push(tx)
+	return true
+}
+
+func (h *history) record(cmd command) {
+	h.redoStack = nil
+	now := h.now()
+
+	if h.current != nil {
+		h.current.commands = merge(h.current.commands, cmd)
+		return
+	}
+
+	if tx := h.coalescing; tx != nil && now.Sub(tx.touched) < coalesceWindow {
+		if coalesce(tx.commands[0], cmd) {
+			tx.touched = now
+			return
+		}
+	}
+
+	tx := &transaction{commands: []command{cmd}, touched: now}
+	h.push(tx)
+
+	h.coalescing = nil
+	if _, ok := cmd.(*editCommand); ok {
+		h.coalescing = tx
+	}
+}
+
+func (h *history) push(tx *transaction) {
+	h.undoStack = append(h.undoStack, tx)
+	if len(h.undoStack) > historyLimit {
+		h.undoStack = h.undoStack[len(h.undoStack)-historyLimit:]
+	}
+}
+
+// merge appends cmd to commands, folding it into an earlier edit
+// of the same node so a drag doesn't keep every intermediate value.
+// Edits only touch style and props, tree commands only the node's
+// place in the tree, so they can be reordered freely.
+func merge(commands []command, cmd command) []command {
+	for _, prev := range commands {
+		if coalesce(prev, cmd) {
+			return commands
+		}
+	}
+
+	return append(commands, cmd)
+}
+
+// coalesce folds next into prev if both edit the same node
+func coalesce(prev, next command) bool {
+	p, ok := prev.(*editCommand)
+	if !ok {
+		return false
+	}
+
+	n, ok := next.(*editCommand)
+	if !ok || p.node != n.node {
+		return false
+	}
+
+	p.afterStyle = n.afterStyle
+	p.afterProps = n.afterProps
+	return true
+}
+
+func (h *history) popUndo() *transaction {
+	n := len(h.undoStack)
+	if n == 0 {
+		return nil
+	}
+
+	h.coalescing = nil
+	tx := h.undoStack[n-1]
+	h.undoStack = h.undoStack[:n-1]
+	h.redoStack = append(h.redoStack, tx)
+	return tx
+}
+
+func (h *history) popRedo() *transaction {
+	n := len(h.redoStack)
+	if n == 0 {
+		return nil
+	}
+
+	h.coalescing = nil
+	tx := h.redoStack[n-1]
+	h.redoStack = h.redoStack[:n-1]
+	h.undoStack = append(h.undoStack, tx)
+	return tx
+}
+
+func (h *history) clear() {
+	h.undoStack = nil
+	h.redoStack = nil
+	h.current = nil
+	h.depth = 0
+	h.coalescing = nil
+}
+
+// --- Engine side ---
+
+// editNode runs edit on node and records it as an undoable step
+func (e *Engine) editNode(node *scene.Node, edit func()) {
+	if node.Style == nil {
+		node.Style = style.NewStyle()
+	}
+
+	cmd := &editCommand{
+		node:        node,
+		beforeStyle: cloneStyle(*node.Style),
+	}
+	if node.Props != nil {
+		cmd.beforeProps = node.Props.Clone()
+	}
+
+	edit()
+
+	cmd.afterStyle = cloneStyle(*node.Style)
+	if node.Props != nil {
+		cmd.afterProps = node.Props.Clone()
+	}
+
+	e.history.record(cmd)
+	e.afterEdit(node)
+}
+
+// cloneStyle copies s with its own grid tracks, so an edit in place
+// doesn't reach the snapshots of the history
+func cloneStyle(s style.Style) style.Style {
+	s.Grid = s.Grid.Clone()
+	return s
+}
+
+// afterEdit keeps spatial index and reconciler in sync with node
+func (e *Engine) afterEdit(node *scene.Node) {
+	// Computed box is stale until the next layout pass, fall back
+	// to the edited Style meanwhile
+	node.ComputedStyle = nil
+	e.reindexSubtree(node)
+
+	node.MarkDirty(scene.FlagLayoutDirty)
+	e.reconciler.ScheduleUpdate(e.rootNode)
+}
+
+func (e *Engine) undo() bool {
+	e.commitOpenTransaction()
+
+	tx := e.history.popUndo()
+	if tx == nil {
+		return false
+	}
+
+	for i := len(tx.commands) - 1; i >= 0; i-- {
+		tx.commands[i].undo(e)
+	}
+	return true
+}
+
+func (e *Engine) redo() bool {
+	e.commitOpenTransaction()
+
+	tx := e.history.popRedo()
+	if tx == nil {
+		return false
+	}
+
+	for _, cmd := range tx.commands {
+		cmd.redo(e)
+	}
+	return true
+}
+
+// Undo/Redo in the middle of a drag closes the drag first
+func (e *Engine) commitOpenTransaction() {
+	for e.history.depth > 0 {
+		e.history.commit()
+	}
+}
@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"engo/internal/algo/rtree"
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+)
+
+// setupHistory loads testDocument with a fake clock that only
+// moves when the test says so
+func setupHistory(t *testing.T) *time.Time {
+	t.Helper()
+
+	Init()
+	loadDocument(t, []byte(testDocument))
+	engine.reconciler.WorkLoop(0)
+
+	now := time.Unix(0, 0)
+	engine.history.now = func() time.Time { return now }
+	return &now
+}
+
+func TestHistory_UndoRedoProp(t *testing.T) {
+	setupHistory(t)
+	frame := engine.findNode(2)
+
+	SetFloatProp(2, PROP_X, 500)
+	if frame.Style.Left != layout.Px(500) {
+		t.Fatalf("SetFloatProp didn't apply, got %v", frame.Style.Left)
+	}
+	engine.reconciler.WorkLoop(0)
+
+	if !Undo() || frame.Style.Left != layout.Px(10) {
+		t.Fatalf("Undo should restore x = 10, got %v", frame.Style.Left)
+	}
+	if engine.reconciler.WipRoot == nil {
+		t.Errorf("Undo must schedule the reconciler")
+	}
+
+	// Frame and its text are back in the spatial index at the old place
+	hits := engine.spatial.Search(rtree.Rect{MinX: 14, MinY: 9, MaxX: 16, MaxY: 11})
+	if len(hits) != 2 {
+		t.Errorf("Spatial index not restored, got %v", hits)
+	}
+
+	if !Redo() || frame.Style.Left != layout.Px(500) {
+		t.Errorf("Redo should apply x = 500 again, got %v", frame.Style.Left)
+	}
+	if Redo() {
+		t.Errorf("Nothing left to redo")
+	}
+}
+
+func TestHistory_GridTracks(t *testing.T) {
+	now := setupHistory(t)
+	frame := engine.findNode(2)
+
+	engine.editNode(frame, func() {
+		frame.Style.Grid.Columns = []layout.Track{layout.PxTrack(100), layout.Fr(1)}
+	})
+	*now = now.Add(time.Second)
+
+	// Track changed in place, the step before keeps its own copy
+	engine.editNode(frame, func() {
+		frame.Style.Grid.Columns[0] = layout.PxTrack(200)
+	})
+
+	if !Undo() || frame.Style.Grid.Columns[0] != layout.PxTrack(100) {
+		t.Fatalf("Undo should restore the 100px track, got %v", frame.Style.Grid.Columns)
+	}
+
+	// Edits after undo don't reach the redo step
+	frame.Style.Grid.Columns[1] = layout.Fr(3)
+	if !Redo() || frame.Style.Grid.Columns[0] != layout.PxTrack(200) || frame.Style.Grid.Columns[1] != layout.Fr(1) {
+		t.Errorf("Redo should apply [200px 1fr] again, got %v", frame.Style.Grid.Columns)
+	}
+}
+
+func TestHistory_RemoveAndCreate(t *testing.T) {
+	now := setupHistory(t)
+	frame := engine.findNode(2)
+	page := engine.rootNode
+
+	RemoveNode(2)
+	if len(page.Children) != 1 || frame.Parent != nil {
+		t.Fatalf("RemoveNode didn't detach the frame")
+	}
+	if len(engine.spatial.Search(rtree.Rect{MinX: 0, MinY: 0, MaxX: 200, MaxY: 200})) != 0 {
+		t.Errorf("Removed subtree still in spatial index")
+	}
+
+	Undo()
+	if len(page.Children) != 2 || page.Children[0] != frame {
+		t.Fatalf("Undo should put the frame back at index 0")
+	}
+
+	*now = now.Add(time.Second)
+	engine.selection = nil
+	if CreateNode(-1, 0) != 0 || CreateNode(1000, 0) != 0 {
+		t.Errorf("Unknown node type should be rejected")
+	}
+	id := CreateNode(int32(scene.Frame), 0)
+	created := engine.findNode(id)
+	if len(page.Children) != 3 || created == nil || created.Type != scene.Frame {
+		t.Fatalf("CreateNode didn't add a frame to page")
+	}
+
+	Undo()
+	if len(page.Children) != 2 || engine.findNode(id) != nil {
+		t.Errorf("Undo should remove the created node")
+	}
+
+	Redo()
+	if len(page.Children) != 3 || page.Children[2] != created || engine.findNode(id) != created {
+		t.Errorf("Redo should add the created node back")
+	}
+}
+
+func TestHistory_Transaction(t *testing.T) {
+	setupHistory(t)
+	frame := engine.findNode(2)
+	image := engine.findNode(4)
+
+	BeginTransaction()
+	for i := 1; i <= 10; i++ {
+		SetFloatProp(2, PROP_X, 10+float32(i))
+		SetFloatProp(4, PROP_Y, 300+float32(i))
+	}
+	CommitTransaction()
+
+	if len(engine.history.undoStack) != 1 {
+		t.Fatalf("Expected one undo step, got %d", len(engine.history.undoStack))
+	}
+
+	Undo()
+	if frame.Style.Left != layout.Px(10) || image.Style.Top != layout.Px(300) {
+		t.Errorf("Undo should revert the whole drag, got x=%v y=%v", frame.Style.Left, image.Style.Top)
+	}
+
+	Redo()
+	if frame.Style.Left != layout.Px(20) || image.Style.Top != layout.Px(310) {
+		t.Errorf("Redo should apply the last values, got x=%v y=%v", frame.Style.Left, image.Style.Top)
+	}
+}
+
+func TestHistory_BatchUpdateFloats(t *testing.T) {
+	setupHistory(t)
+
+	AllocBatchBuffer(2)
+	copy(engine.batchBuffer, []uint32{
+		2, uint32(PROP_WIDTH), math.Float32bits(42),
+		4, uint32(PROP_OPACITY), math.Float32bits(0.5),
+	})
+	BatchUpdateFloats(2)
+
+	if engine.findNode(2).Style.Width != layout.Px(42) || engine.findNode(4).Style.Opacity != 0.5 {
+		t.Fatalf("Batch not applied")
+	}
+
+	Undo()
+	if engine.findNode(2).Style.Width != layout.Px(100) || engine.findNode(4).Style.Opacity != 1 {
+		t.Errorf("Batch should be one undo step")
+	}
+}
+
+func TestHistory_Coalesce(t *testing.T) {
+	now := setupHistory(t)
+	frame := engine.findNode(2)
+
+	SetFloatProp(2, PROP_X, 11)
+	*now = now.Add(100 * time.Millisecond)
+	SetFloatProp(2, PROP_X, 12)
+	*now = now.Add(100 * time.Millisecond)
+	SetFloatProp(2, PROP_X, 13)
+
+	// Too late to merge
+	*now = now.Add(time.Second)
+	SetFloatProp(2, PROP_X, 14)
+
+	if len(engine.history.undoStack) != 2 {
+		t.Fatalf("Expected 2 undo steps, got %d", len(engine.history.undoStack))
+	}
+
+	Undo()
+	if frame.Style.Left != layout.Px(13) {
+		t.Errorf("Expected 13, got %v", frame.Style.Left)
+	}
+	Undo()
+	if frame.Style.Left != layout.Px(10) {
+		t.Errorf("Expected 10, got %v", frame.Style.Left)
+	}
+
+	// New edit after undo drops the redo stack
+	SetFloatProp(2, PROP_Y, 0)
+	if Redo() {
+		t.Errorf("Redo stack should be cleared by a new edit")
+	}
+}
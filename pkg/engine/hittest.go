@@ -0,0 +1,262 @@
+package engine
+
+import (
+	"slices"
+
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+// Lines are thin, they are hit this close (screen pixel)
+const hitSlop = 4
+
+// screenToWorld maps a point of the canvas (CSS pixel) to world space
+func (e *Engine) screenToWorld(x, y float32) (float32, float32) {
+	return e.viewport.ScreenToWorld(x, y)
+}
+
+// viewScale is the zoom of the view, screen pixels per world unit
+func (e *Engine) viewScale() float32 {
+	return e.viewport.Scale
+}
+
+// hitTest returns the topmost node at world point (x, y). Unless deep,
+// the node is lifted to its ancestor directly inside the selection scope,
+// or inside the page if it's out of the scope, like a click in Figma.
+func (e *Engine) hitTest(x, y float32, deep bool) *scene.Node {
+	slop := float64(hitSlop / e.viewScale())
+	area := rtree.Rect{
+		MinX: float64(x) - slop, MinY: float64(y) - slop,
+		MaxX: float64(x) + slop, MaxY: float64(y) + slop,
+	}
+
+	var top *scene.Node
+	for _, data := range e.spatial.Search(area) {
+		node := e.findNode(data.(uint32))
+		if node == nil || (top != nil && !paintsAbove(node, top)) || !e.hits(node, x, y) {
+			continue
+		}
+		top = node
+	}
+
+	if top == nil || deep {
+		return top
+	}
+	return e.liftToScope(top)
+}
+
+// liftToScope returns the ancestor of node (or node itself) whose parent
+// is the insert target, the child of the page if node is out of it
+func (e *Engine) liftToScope(node *scene.Node) *scene.Node {
+	scope := e.GetInsertTarget()
+
+	lifted := node
+	for n := node; n.Parent != nil; n = n.Parent {
+		if n.Parent == scope {
+			return n
+		}
+		if n.Parent == e.rootNode {
+			lifted = n
+		}
+	}
+	return lifted
+}
+
+// hits reports whether world point (x, y) is on node: the node and its
+// ancestors are visible and unlocked, no ancestor clips the point away
+// and the point is inside the shape of node
+func (e *Engine) hits(node *scene.Node, x, y float32) bool {
+	// Groups have no shape of their own, only their children are hit
+	if node.Type == scene.Page || node.Type == scene.Group || node.Type == scene.Section {
+		return false
+	}
+
+	for n := node; n != nil; n = n.Parent {
+		if n.Hidden || n.Locked {
+			return false
+		}
+		if n != node && n.Style != nil && n.Style.Overflow != style.OverflowVisible && !e.contains(n, x, y) {
+			return false
+		}
+	}
+
+	return e.contains(node, x, y)
+}
+
+// contains reports whether world point (x, y) is inside the shape of node
+func (e *Engine) contains(node *scene.Node, x, y float32) bool {
+	inverse, ok := worldMatrix(node).Invert()
+	if !ok {
+		return false
+	}
+	lx, ly := inverse.Apply(x, y)
+	_, _, w, h := node.Box()
+
+	if node.Type == scene.Line {
+		// Segment along the middle of the box
+		reach := hitSlop / e.viewScale()
+		if rect, ok := node.Props.(*scene.RectProps); ok {
+			reach = max(reach, rect.StrokeWidth/2)
+		}
+		return lx >= -reach && lx <= w+reach && abs(ly-h/2) <= reach
+	}
+
+	rect, ok := node.Props.(*scene.RectProps)
+	if !ok {
+		return inBox(lx, ly, w, h)
+	}
+
+	switch rect.Shape {
+	case scene.ShapeEllipse:
+		if w <= 0 || h <= 0 {
+			return false
+		}
+		dx, dy := (lx-w/2)/(w/2), (ly-h/2)/(h/2)
+		return dx*dx+dy*dy <= 1
+	case scene.ShapePath:
+		return inPath(rect.Path, lx/w, ly/h)
+	}
+	return inRoundedRect(lx, ly, w, h, rect.CornerRadius)
+}
+
+func inBox(x, y, w, h float32) bool {
+	return x >= 0 && y >= 0 && x <= w && y <= h
+}
+
+// inRoundedRect tests against a w x h box with radii top-left,
+// top-right, bottom-right, bottom-left
+func inRoundedRect(x, y, w, h float32, radii [4]float32) bool {
+	if !inBox(x, y, w, h) {
+		return false
+	}
+
+	limit := min(w, h) / 2
+	corners := [4][2]float32{{0, 0}, {w, 0}, {w, h}, {0, h}}
+	for i, r := range radii {
+		r = min(r, limit)
+		if r <= 0 {
+			continue
+		}
+
+		// Center of the corner arc, inside the box
+		cx, cy := corners[i][0], corners[i][1]
+		if cx == 0 {
+			cx = r
+		} else {
+			cx -= r
+		}
+		if cy == 0 {
+			cy = r
+		} else {
+			cy -= r
+		}
+
+		// Only the square outside the arc center can miss
+		outX := (i == 0 || i == 3) && x < cx || (i == 1 || i == 2) && x > cx
+		outY := (i == 0 || i == 1) && y < cy || (i == 2 || i == 3) && y > cy
+		if outX && outY && (x-cx)*(x-cx)+(y-cy)*(y-cy) > r*r {
+			return false
+		}
+	}
+	return true
+}
+
+// inPath tests against the closed polygon of path with the even-odd rule
+func inPath(path []float32, x, y float32) bool {
+	n := len(path) / 2
+	if n < 3 {
+		return false
+	}
+
+	inside := false
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		xi, yi := path[2*i], path[2*i+1]
+		xj, yj := path[2*j], path[2*j+1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// paintsAbove reports whether a is drawn after b: later siblings paint
+// over earlier ones and children over their parent
+func paintsAbove(a, b *scene.Node) bool {
+	pa, pb := paintPath(a), paintPath(b)
+	for i := range min(len(pa), len(pb)) {
+		if pa[i] != pb[i] {
+			return pa[i] > pb[i]
+		}
+	}
+	return len(pa) > len(pb)
+}
+
+// paintPath returns the child indices from the page down to node
+func paintPath(node *scene.Node) []int {
+	var path []int
+	for ; node.Parent != nil; node = node.Parent {
+		path = append(path, slices.Index(node.Parent.Children, node))
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// mouseAction selects what is under the pointer when the left button
+// goes down, Ctrl or Cmd selects the deepest node and Shift toggles it in
+// the selection. A press on a handle of the selection resizes or rotates
+// it, on a node moves the selection, on the empty canvas starts a
+// marquee. Space or the middle button pans instead. Drags end with the
+// button.
+func (e *Engine) mouseAction(button, action int32, modifiers uint32) {
+	if action != 0 {
+		e.endMarquee()
+		e.endTransform()
+		e.pan.active = false
+		return
+	}
+	if button == 1 || button == 0 && e.pan.space {
+		e.startPan()
+		return
+	}
+	if button != 0 {
+		return
+	}
+
+	x, y := e.screenToWorld(e.inputState.Pointer())
+	if handle := e.handleAt(x, y); handle&handleRotate != 0 {
+		e.startTransform(dragRotate, handle, x, y)
+		return
+	} else if handle != 0 {
+		e.startTransform(dragResize, handle, x, y)
+		return
+	}
+
+	node := e.hitTest(x, y, modifiers&(protocol.ModCtrl|protocol.ModMeta) != 0)
+	if node == nil {
+		e.startMarquee(x, y, modifiers)
+		return
+	}
+
+	// Pressing a selected node drags the whole selection, with Shift
+	// a click without drag deselects it
+	selected := slices.Contains(e.selection, node)
+	switch {
+	case !selected && modifiers&protocol.ModShift != 0:
+		e.selectNode(node, SELECT_ADD)
+	case !selected:
+		e.selectNode(node, SELECT_REPLACE)
+	}
+	e.startTransform(dragMove, 0, x, y)
+	if selected && modifiers&protocol.ModShift != 0 {
+		e.transform.toggle = node
+	}
+}
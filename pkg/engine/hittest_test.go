@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+
+	"engo/internal/protocol"
+)
+
+const hitDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":0,"top":0,"width":200,"height":200,"overflow":1},"children":[
+		{"id":3,"type":"FRAME","style":{"left":10,"top":10,"width":50,"height":50}},
+		{"id":4,"type":"POLYGON","style":{"left":100,"top":0,"width":100,"height":100},"props":{"shape":1}},
+		{"id":5,"type":"POLYGON","style":{"left":150,"top":150,"width":100,"height":100}}
+	]},
+	{"id":6,"type":"FRAME","style":{"left":300,"top":0,"width":100,"height":100,"rotation":45}},
+	{"id":7,"type":"GROUP","style":{"left":500,"top":0,"width":100,"height":100},"children":[
+		{"id":8,"type":"FRAME","style":{"left":0,"top":0,"width":100,"height":100}},
+		{"id":9,"type":"FRAME","hidden":true,"style":{"left":0,"top":0,"width":100,"height":100}}
+	]},
+	{"id":10,"type":"FRAME","locked":true,"style":{"left":700,"top":0,"width":100,"height":100}},
+	{"id":11,"type":"POLYGON","style":{"left":800,"top":0,"width":100,"height":100},"props":{"shape":2,"path":[0,0,1,0,0,1]}},
+	{"id":12,"type":"FRAME","style":{"left":0,"top":0,"width":50,"height":50},"props":{"cornerRadius":[0,0,20,0]}},
+	{"id":13,"type":"LINE","style":{"left":0,"top":300,"width":100,"height":0}}
+]}}`
+
+func TestHitTest(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(hitDocument))
+	Update(0)
+
+	cases := []struct {
+		name          string
+		x, y          float32
+		shallow, deep uint32
+	}{
+		{"topmost sibling", 20, 20, 12, 12},
+		{"rounded corner", 48, 48, 2, 3},
+		{"child lifts to its frame", 55, 55, 2, 3},
+		{"outside the ellipse", 105, 5, 2, 2},
+		{"inside the ellipse", 150, 50, 2, 4},
+		{"inside the clip", 190, 190, 2, 5},
+		{"clipped away", 240, 240, 0, 0},
+		{"corner rotated away", 305, 5, 0, 0},
+		{"rotated outside the layout box", 350, -15, 6, 6},
+		{"group and hidden child", 550, 50, 7, 8},
+		{"locked", 750, 50, 0, 0},
+		{"inside the path", 810, 10, 11, 11},
+		{"outside the path", 890, 90, 0, 0},
+		{"near the line", 50, 302, 13, 13},
+	}
+
+	for _, c := range cases {
+		if id := HitTest(c.x, c.y); id != c.shallow {
+			t.Errorf("%s: HitTest expected %d, got %d", c.name, c.shallow, id)
+		}
+		if id := HitTestDeep(c.x, c.y); id != c.deep {
+			t.Errorf("%s: HitTestDeep expected %d, got %d", c.name, c.deep, id)
+		}
+	}
+
+	// Siblings of the selection are hit directly
+	engine.SetSelection(engine.findNode(4), true)
+	if id := HitTest(55, 55); id != 3 {
+		t.Errorf("Expected sibling 3 inside the scope, got %d", id)
+	}
+}
+
+func TestOnMouseAction_Select(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(hitDocument))
+	Update(0)
+
+	input := unsafe.Slice((*uint32)(engine.inputState.GetPtr()), protocol.InputWords)
+	input[protocol.InputPointerX] = math.Float32bits(55)
+	input[protocol.InputPointerY] = math.Float32bits(55)
+
+	OnMouseAction(0, 0, 0)
+	if len(engine.selection) != 1 || engine.selection[0].ID != 2 {
+		t.Errorf("Click should select frame 2, got %v", engine.selection)
+	}
+
+	ClearSelection()
+	OnMouseAction(0, 0, int32(protocol.ModCtrl))
+	if len(engine.selection) != 1 || engine.selection[0].ID != 3 {
+		t.Errorf("Ctrl click should select frame 3, got %v", engine.selection)
+	}
+
+	input[protocol.InputPointerX] = math.Float32bits(240)
+	input[protocol.InputPointerY] = math.Float32bits(240)
+	OnMouseAction(0, 0, 0)
+	if len(engine.selection) != 0 {
+		t.Errorf("Click on empty canvas should clear the selection")
+	}
+}
@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"math"
+	"slices"
+
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+	"engo/pkg/scene"
+)
+
+// Below this drag distance (screen pixel) a press on the empty canvas
+// is a click, not a marquee
+const dragThreshold = 3
+
+// Figma blue
+var (
+	marqueeFill   = protocol.Color(24, 160, 251, 32)
+	marqueeStroke = protocol.Color(24, 160, 251, 255)
+)
+
+// marquee is a rubber-band selection being dragged
+type marquee struct {
+	active bool
+	// Passed the drag threshold, the rectangle is drawn and selects
+	moved bool
+	// Corners in world space, start is where the drag began
+	startX, startY float32
+	endX, endY     float32
+	// Only children of scope are picked
+	scope *scene.Node
+	// Selection when the drag began, Shift toggles the picked nodes in it
+	base []*scene.Node
+}
+
+func (m *marquee) rect() rtree.Rect {
+	return rtree.Rect{
+		MinX: float64(min(m.startX, m.endX)), MinY: float64(min(m.startY, m.endY)),
+		MaxX: float64(max(m.startX, m.endX)), MaxY: float64(max(m.startY, m.endY)),
+	}
+}
+
+// startMarquee begins a drag at world point (x, y), the selection is
+// cleared first unless Shift is held
+func (e *Engine) startMarquee(x, y float32, modifiers uint32) {
+	// Scope comes from the selection before it is cleared
+	scope := e.GetInsertTarget()
+	if modifiers&protocol.ModShift == 0 {
+		e.setSelection(nil)
+	}
+
+	e.marquee = marquee{
+		active: true,
+		startX: x, startY: y,
+		endX: x, endY: y,
+		scope: scope,
+		base:  slices.Clone(e.selection),
+	}
+}
+
+// dragMarquee follows the pointer of this frame and selects again,
+// Alt picks only the nodes fully inside the rectangle
+func (e *Engine) dragMarquee() {
+	m := &e.marquee
+	if !m.active {
+		return
+	}
+
+	m.endX, m.endY = e.screenToWorld(e.input.X, e.input.Y)
+
+	if !m.moved {
+		dist := math.Hypot(float64(m.endX-m.startX), float64(m.endY-m.startY))
+		m.moved = float32(dist)*e.viewScale() >= dragThreshold
+	}
+	if m.moved {
+		e.selectInMarquee(e.input.Modifiers)
+	}
+
+	if e.input.Released&protocol.ButtonLeft != 0 {
+		e.endMarquee()
+	}
+}
+
+func (e *Engine) selectInMarquee(modifiers uint32) {
+	m := &e.marquee
+	area := m.rect()
+	contain := modifiers&protocol.ModAlt != 0
+
+	var picked []*scene.Node
+	for _, data := range e.spatial.Search(area) {
+		node := e.findNode(data.(uint32))
+		if node == nil || node.Parent != m.scope || node.Hidden || node.Locked {
+			continue
+		}
+		if contain && !containsRect(area, node.LastWorldMBR) {
+			continue
+		}
+		picked = append(picked, node)
+	}
+	slices.SortFunc(picked, func(a, b *scene.Node) int {
+		if paintsAbove(a, b) {
+			return 1
+		}
+		return -1
+	})
+
+	if modifiers&protocol.ModShift == 0 {
+		e.setSelection(picked)
+		return
+	}
+
+	// Picked nodes already selected are removed, the others added
+	selection := make([]*scene.Node, 0, len(m.base)+len(picked))
+	for _, node := range m.base {
+		if !slices.Contains(picked, node) {
+			selection = append(selection, node)
+		}
+	}
+	for _, node := range picked {
+		if !slices.Contains(m.base, node) {
+			selection = append(selection, node)
+		}
+	}
+	e.setSelection(selection)
+}
+
+func (e *Engine) endMarquee() {
+	e.marquee = marquee{}
+}
+
+func containsRect(outer, inner rtree.Rect) bool {
+	return inner.MinX >= outer.MinX && inner.MinY >= outer.MinY &&
+		inner.MaxX <= outer.MaxX && inner.MaxY <= outer.MaxY
+}
+
+// drawMarquee draws the rectangle being dragged, the stroke stays one
+// screen pixel wide at any zoom
+func (e *Engine) drawMarquee() {
+	m := &e.marquee
+	if !m.active || !m.moved {
+		return
+	}
+
+	r := m.rect()
+	cb := e.commandBuffer
+	cb.WriteSetMatrix(e.viewport.Matrix())
+	cb.WriteSetFill(marqueeFill)
+	cb.WriteSetStroke(marqueeStroke, 1/e.viewScale())
+	cb.WriteDrawRect(float32(r.MinX), float32(r.MinY), float32(r.MaxX-r.MinX), float32(r.MaxY-r.MinY),
+		protocol.DrawFill|protocol.DrawStroke)
+}
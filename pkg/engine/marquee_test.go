@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"unsafe"
+
+	"engo/internal/protocol"
+)
+
+const marqueeDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":0,"top":0,"width":100,"height":100},"children":[
+		{"id":3,"type":"POLYGON","style":{"left":10,"top":10,"width":20,"height":20}},
+		{"id":6,"type":"POLYGON","style":{"left":40,"top":40,"width":20,"height":20}}
+	]},
+	{"id":4,"type":"FRAME","style":{"left":150,"top":0,"width":100,"height":100}},
+	{"id":5,"type":"FRAME","locked":true,"style":{"left":300,"top":0,"width":100,"height":100}}
+]}}`
+
+// pointerTo moves the pointer and buttons the way the JS glue does
+func pointerTo(x, y float32, buttons, modifiers uint32) {
+	input := unsafe.Slice((*uint32)(engine.inputState.GetPtr()), protocol.InputWords)
+	input[protocol.InputPointerX] = math.Float32bits(x)
+	input[protocol.InputPointerY] = math.Float32bits(y)
+	input[protocol.InputButtons] = buttons
+	input[protocol.InputModifiers] = modifiers
+}
+
+func selectedIDs() []uint32 {
+	var ids []uint32
+	for _, node := range engine.selection {
+		ids = append(ids, node.ID)
+	}
+	return ids
+}
+
+// drag presses on (x0, y0), moves to (x1, y1) over one Update, then
+// leaves the button down
+func drag(x0, y0, x1, y1 float32, modifiers uint32) {
+	pointerTo(x0, y0, protocol.ButtonLeft, modifiers)
+	Update(0)
+	OnMouseAction(0, 0, int32(modifiers))
+	pointerTo(x1, y1, protocol.ButtonLeft, modifiers)
+	Update(0)
+}
+
+func TestMarquee(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(marqueeDocument))
+	Update(0)
+
+	// Crosses 2 and 4 but not the locked 5, child 3 is out of the scope
+	drag(-10, 120, 350, 50, 0)
+	if ids := selectedIDs(); !slices.Equal(ids, []uint32{2, 4}) {
+		t.Errorf("Expected [2 4], got %v", ids)
+	}
+
+	// Rectangle is drawn while dragging
+	data := engine.commandBuffer.Data
+	if !slices.ContainsFunc(data, func(w uint32) bool { return protocol.OpCode(w&0xFF) == protocol.OpDrawRect && w>>8 == 5 }) {
+		t.Errorf("Marquee should be drawn, got %v", data)
+	}
+
+	// Alt only picks nodes fully inside
+	pointerTo(350, 50, protocol.ButtonLeft, protocol.ModAlt)
+	Update(0)
+	if ids := selectedIDs(); len(ids) != 0 {
+		t.Errorf("Nothing is fully inside yet, got %v", ids)
+	}
+	pointerTo(200, -10, protocol.ButtonLeft, protocol.ModAlt)
+	Update(0)
+	if ids := selectedIDs(); !slices.Equal(ids, []uint32{2}) {
+		t.Errorf("Expected [2], 4 is only crossed, got %v", ids)
+	}
+
+	pointerTo(200, -10, 0, 0)
+	Update(0)
+	if engine.marquee.active || slices.Contains(engine.commandBuffer.Data, marqueeFill) {
+		t.Errorf("Release should end the marquee")
+	}
+}
+
+func TestMarquee_Scope(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(marqueeDocument))
+	Update(0)
+
+	// Siblings of the selection are picked, not frame 2 around them
+	engine.SetSelection(engine.findNode(3), true)
+	drag(-20, -20, 50, 50, 0)
+	if ids := selectedIDs(); !slices.Equal(ids, []uint32{3, 6}) {
+		t.Errorf("Expected [3 6] inside frame 2, got %v", ids)
+	}
+	pointerTo(50, 50, 0, 0)
+	Update(0)
+
+	// Shift keeps the selection and toggles what the rectangle crosses
+	engine.SetSelection(engine.findNode(3), true)
+	drag(-20, -20, 50, 50, protocol.ModShift)
+	if ids := selectedIDs(); !slices.Equal(ids, []uint32{6}) {
+		t.Errorf("Expected 3 toggled off and 6 added, got %v", ids)
+	}
+
+	// A press without drag is a click on the canvas
+	OnMouseAction(0, 1, 0)
+	drag(500, 500, 501, 501, 0)
+	if len(engine.selection) != 0 || !engine.marquee.active || engine.marquee.moved {
+		t.Errorf("Short drag should only clear the selection")
+	}
+}
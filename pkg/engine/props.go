@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+)
+
+// Prop codes shared with JS for the generic setters
+// (SetFloatProp, SetDimensionProp, BatchUpdateFloats...)
+const (
+	PROP_X int32 = iota + 1
+	PROP_Y
+	PROP_WIDTH
+	PROP_HEIGHT
+	PROP_OPACITY
+	PROP_RADIUS
+	PROP_MIN_WIDTH
+	PROP_MAX_WIDTH
+	PROP_MIN_HEIGHT
+	PROP_MAX_HEIGHT
+	PROP_ASPECT_RATIO
+	// Colors, animations only
+	PROP_FILL
+	PROP_STROKE
+)
+
+// setFloatProp writes value into the style or props field of propCode,
+// returns false if node has no such property
+func setFloatProp(node *scene.Node, propCode int32, value float32) bool {
+	s := node.Style
+
+	switch propCode {
+	case PROP_X:
+		s.Left = layout.Px(value)
+	case PROP_Y:
+		s.Top = layout.Px(value)
+	case PROP_WIDTH:
+		s.Width = layout.Px(value)
+	case PROP_HEIGHT:
+		s.Height = layout.Px(value)
+	case PROP_OPACITY:
+		s.Opacity = value
+	case PROP_MIN_WIDTH:
+		s.MinWidth = layout.Px(value)
+	case PROP_MAX_WIDTH:
+		s.MaxWidth = layout.Px(value)
+	case PROP_MIN_HEIGHT:
+		s.MinHeight = layout.Px(value)
+	case PROP_MAX_HEIGHT:
+		s.MaxHeight = layout.Px(value)
+	case PROP_ASPECT_RATIO:
+		s.AspectRatio = value
+	case PROP_RADIUS:
+		rect, ok := node.Props.(*scene.RectProps)
+		if !ok {
+			return false
+		}
+		rect.CornerRadius = [4]float32{value, value, value, value}
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+	"engo/pkg/fiber"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+	"engo/pkg/text"
+)
+
+// Screen pixels drawn around the canvas, so small pans don't show
+// nodes popping in before the next frame
+const cullMargin = 256
+
+// What culling found for a node
+const (
+	// Its box is on screen
+	cullSelf uint8 = 1 << iota
+	// Something below it is on screen
+	cullSubtree
+)
+
+// render rewrites the command buffer with the frame of the committed
+// scene, JS reads it after every Update
+func (e *Engine) render() {
+	e.commandBuffer.Reset()
+	// Camera of the frame, JS applies it as is
+	e.commandBuffer.WriteSetMatrix(e.viewport.Matrix())
+	e.drawScene()
+	e.drawSelection()
+	e.drawGuides()
+	e.drawMarquee()
+	e.commandBuffer.WriteEof()
+}
+
+// visibleRect returns the world rect of the canvas and its margin,
+// false while JS has not told the canvas size
+func (e *Engine) visibleRect() (rtree.Rect, bool) {
+	v := e.viewport
+	if v.Width <= 0 || v.Height <= 0 {
+		return rtree.Rect{}, false
+	}
+
+	x0, y0 := v.ScreenToWorld(-cullMargin, -cullMargin)
+	x1, y1 := v.ScreenToWorld(v.Width+cullMargin, v.Height+cullMargin)
+	return rtree.Rect{MinX: float64(x0), MinY: float64(y0), MaxX: float64(x1), MaxY: float64(y1)}, true
+}
+
+// cull marks the nodes whose box is in area and their ancestors. A
+// child can overflow its parent, so subtrees are skipped only when
+// nothing in them was found, not when their root is off screen.
+func (e *Engine) cull(area rtree.Rect) {
+	if e.visible == nil {
+		e.visible = make(map[*scene.Node]uint8)
+	}
+	clear(e.visible)
+
+	for _, data := range e.spatial.Search(area) {
+		node := e.findNode(data.(uint32))
+		if node == nil {
+			continue
+		}
+
+		e.visible[node] |= cullSelf
+		for p := node.Parent; p != nil && e.visible[p]&cullSubtree == 0; p = p.Parent {
+			e.visible[p] |= cullSubtree
+		}
+	}
+}
+
+// drawScene draws the committed fiber tree in paint order, only what is
+// on screen
+func (e *Engine) drawScene() {
+	root := e.reconciler.CurrentRoot
+	if root == nil {
+		return
+	}
+
+	area, culled := e.visibleRect()
+	if culled {
+		e.cull(area)
+	}
+	for f := root.Child; f != nil; f = f.Sibling {
+		e.drawFiber(f, culled)
+	}
+}
+
+// drawFiber draws a node then its children inside a group that holds
+// the clip and opacity of the node
+func (e *Engine) drawFiber(f *fiber.Fiber, culled bool) {
+	node := f.Node
+	if node == nil || node.Hidden {
+		return
+	}
+
+	state := cullSelf | cullSubtree
+	if culled {
+		state = e.visible[node]
+	}
+	opacity := float32(1)
+	if node.Style != nil {
+		opacity = node.Style.Opacity
+	}
+	if state == 0 || opacity <= 0 {
+		return
+	}
+
+	children := f.Child != nil && state&cullSubtree != 0
+	group := children || opacity < 1
+	if group {
+		e.commandBuffer.WritePushGroup(opacity)
+	}
+
+	clips := children && node.Style != nil && node.Style.Overflow != style.OverflowVisible
+	draws := state&cullSelf != 0 && shows(node)
+	if draws || clips {
+		e.commandBuffer.WriteSetMatrix(e.viewport.Matrix().Multiply(f.GlobalMatrix))
+	}
+	if draws {
+		e.drawNode(f)
+	}
+
+	if children {
+		if clips {
+			e.commandBuffer.WriteClipRect(0, 0, f.LayoutW, f.LayoutH)
+		}
+		for child := f.Child; child != nil; child = child.Sibling {
+			e.drawFiber(child, culled)
+		}
+	}
+	if group {
+		e.commandBuffer.WritePopGroup()
+	}
+}
+
+// shows says whether node has anything to draw itself, groups and
+// nodes without fill or stroke don't
+func shows(node *scene.Node) bool {
+	switch props := node.Props.(type) {
+	case *scene.RectProps:
+		return props.Fill>>24 != 0 || props.Stroke>>24 != 0 && props.StrokeWidth > 0
+	case *scene.TextProps:
+		return props.Fill>>24 != 0 && props.Content != ""
+	case *scene.ImageProps:
+		return true
+	}
+	return false
+}
+
+// drawNode draws the primitive of one node in its own space, the
+// matrix is already set
+func (e *Engine) drawNode(f *fiber.Fiber) {
+	w, h := f.LayoutW, f.LayoutH
+	switch props := f.Node.Props.(type) {
+	case *scene.RectProps:
+		e.drawShape(f.Node.Type, props, w, h)
+	case *scene.TextProps:
+		e.drawText(props, w)
+	case *scene.ImageProps:
+		e.commandBuffer.WriteDrawImage(props.TextureID, 0, 0, w, h, uint32(props.ScaleMode))
+	}
+}
+
+// paint sets the fill and stroke of props and returns how to draw with
+// them
+func (e *Engine) paint(props *scene.RectProps) protocol.DrawMode {
+	var mode protocol.DrawMode
+	if props.Fill>>24 != 0 {
+		e.commandBuffer.WriteSetFill(props.Fill)
+		mode |= protocol.DrawFill
+	}
+	if props.Stroke>>24 != 0 && props.StrokeWidth > 0 {
+		e.commandBuffer.WriteSetStroke(props.Stroke, props.StrokeWidth)
+		mode |= protocol.DrawStroke
+	}
+	return mode
+}
+
+// drawShape draws the outline hit testing uses, see contains
+func (e *Engine) drawShape(nodeType scene.NodeType, props *scene.RectProps, w, h float32) {
+	mode := e.paint(props)
+	cb := e.commandBuffer
+	if nodeType == scene.Line {
+		if mode&protocol.DrawStroke != 0 {
+			cb.WriteDrawLine(0, h/2, w, h/2)
+		}
+		return
+	}
+
+	switch props.Shape {
+	case scene.ShapeEllipse:
+		cb.WriteDrawOval(0, 0, w, h, mode)
+	case scene.ShapePath:
+		if len(props.Path) < 6 {
+			return
+		}
+		cb.WritePathBegin()
+		cb.WritePathMove(props.Path[0]*w, props.Path[1]*h)
+		for i := 2; i+1 < len(props.Path); i += 2 {
+			cb.WritePathLine(props.Path[i]*w, props.Path[i+1]*h)
+		}
+		cb.WritePathClose()
+		if mode&protocol.DrawFill != 0 {
+			cb.WritePathFill()
+		}
+		if mode&protocol.DrawStroke != 0 {
+			cb.WritePathStroke()
+		}
+	default:
+		if props.CornerRadius == [4]float32{} {
+			cb.WriteDrawRect(0, 0, w, h, mode)
+			return
+		}
+		cb.WriteDrawRRect(0, 0, w, h, props.CornerRadius, mode)
+	}
+}
+
+// drawText draws the lines text layout breaks the content into at the
+// width of the box, the same lines it was measured with
+func (e *Engine) drawText(props *scene.TextProps, w float32) {
+	font := e.fonts.Lookup(props.FontFamily)
+	block := font.Layout(props.Content, text.Style{
+		Size:          props.FontSize,
+		LineHeight:    props.LineHeight,
+		LetterSpacing: props.LetterSpacing,
+	}, w)
+
+	cb := e.commandBuffer
+	cb.WriteSetFill(props.Fill)
+	cb.WriteSetFont(props.FontSize, props.LetterSpacing, props.FontFamily)
+	for i, line := range block.Lines {
+		x := float32(0)
+		switch props.Align {
+		case scene.TextAlignCenter:
+			x = (w - line.Width) / 2
+		case scene.TextAlignRight:
+			x = w - line.Width
+		}
+		y := block.Baseline + float32(i)*block.LineHeight
+		cb.WriteDrawText(x, y, props.Content[line.Start:line.End])
+	}
+}
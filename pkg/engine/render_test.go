@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"engo/internal/protocol"
+	"engo/pkg/layout"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+// Name and words of every op render writes: f float, u uint, c color,
+// m draw mode, s string up to the end
+var opFormats = map[protocol.OpCode]struct{ name, words string }{
+	protocol.OpEof:        {"Eof", ""},
+	protocol.OpPushGroup:  {"PushGroup", "f"},
+	protocol.OpPopGroup:   {"PopGroup", ""},
+	protocol.OpSetMatrix:  {"SetMatrix", "ffffff"},
+	protocol.OpClipRect:   {"ClipRect", "ffff"},
+	protocol.OpSetFill:    {"SetFill", "c"},
+	protocol.OpSetStroke:  {"SetStroke", "cf"},
+	protocol.OpDrawRect:   {"DrawRect", "ffffm"},
+	protocol.OpDrawRRect:  {"DrawRRect", "ffffffffm"},
+	protocol.OpDrawOval:   {"DrawOval", "ffffm"},
+	protocol.OpDrawLine:   {"DrawLine", "ffff"},
+	protocol.OpPathBegin:  {"PathBegin", ""},
+	protocol.OpPathMove:   {"PathMove", "ff"},
+	protocol.OpPathLine:   {"PathLine", "ff"},
+	protocol.OpPathClose:  {"PathClose", ""},
+	protocol.OpPathFill:   {"PathFill", ""},
+	protocol.OpPathStroke: {"PathStroke", ""},
+	protocol.OpDrawImg:    {"DrawImg", "uffffu"},
+	protocol.OpSetFont:    {"SetFont", "ffs"},
+	protocol.OpDrawText:   {"DrawText", "ffs"},
+}
+
+// disassemble prints the command buffer one op per line
+func disassemble(t *testing.T, data []uint32) string {
+	t.Helper()
+
+	var b strings.Builder
+	for i := 0; i < len(data); {
+		op, size := protocol.OpCode(data[i]&0xFF), int(data[i]>>8)
+		format, ok := opFormats[op]
+		if !ok {
+			t.Fatalf("Unknown op 0x%02x at word %d", uint32(op), i)
+		}
+
+		b.WriteString(format.name)
+		payload := data[i+1 : i+1+size]
+		for j, kind := range format.words {
+			w := payload[j]
+			switch kind {
+			case 'f':
+				v := math.Float32frombits(w)
+				fmt.Fprintf(&b, " %g", math.Round(float64(v)*1000)/1000)
+			case 'u':
+				fmt.Fprintf(&b, " %d", w)
+			case 'c':
+				fmt.Fprintf(&b, " #%02x%02x%02x%02x", w&0xFF, w>>8&0xFF, w>>16&0xFF, w>>24)
+			case 'm':
+				mode := protocol.DrawMode(w)
+				b.WriteString(" ")
+				if mode&protocol.DrawFill != 0 {
+					b.WriteString("fill")
+				}
+				if mode == protocol.DrawFill|protocol.DrawStroke {
+					b.WriteString("+")
+				}
+				if mode&protocol.DrawStroke != 0 {
+					b.WriteString("stroke")
+				}
+			case 's':
+				raw := make([]byte, w)
+				for k := range raw {
+					raw[k] = byte(payload[j+1+k/4] >> (8 * (k % 4)))
+				}
+				fmt.Fprintf(&b, " %q", raw)
+			}
+		}
+		b.WriteByte('\n')
+		i += size + 1
+	}
+	return b.String()
+}
+
+// TestRender_Golden draws every document of testdata/render and
+// compares the frame with its .golden file, go test -update rewrites them
+func TestRender_Golden(t *testing.T) {
+	docs, err := filepath.Glob("testdata/render/*.json")
+	if err != nil || len(docs) == 0 {
+		t.Fatalf("No documents in testdata/render: %v", err)
+	}
+
+	for _, doc := range docs {
+		name := strings.TrimSuffix(filepath.Base(doc), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			Init()
+			loadDocument(t, data)
+			Update(0)
+			got := disassemble(t, engine.commandBuffer.Data)
+
+			golden := strings.TrimSuffix(doc, ".json") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Missing golden file, run go test -update: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("Frame differs from %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+// Opaque fills with the id in red tell the nodes apart in the command
+// buffer. Child 5 overflows its off-screen parent onto the canvas.
+const cullDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":0,"top":0,"width":100,"height":100},"props":{"fill":4278190082}},
+	{"id":3,"type":"FRAME","style":{"left":5000,"top":0,"width":100,"height":100},"props":{"fill":4278190083}},
+	{"id":4,"type":"FRAME","style":{"left":-3000,"top":0,"width":100,"height":100},"props":{"fill":4278190084},"children":[
+		{"id":5,"type":"FRAME","style":{"left":3000,"top":200,"width":100,"height":100},"props":{"fill":4278190085}}
+	]}
+]}}`
+
+// drawnFills returns the ids of the fills set while drawing, in order
+func drawnFills() []uint32 {
+	var fills []uint32
+	for _, payload := range commands(protocol.OpSetFill) {
+		fills = append(fills, payload[0]&0xFF)
+	}
+	return fills
+}
+
+func TestRender_Culling(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(cullDocument))
+	Update(0)
+
+	// Size unknown, everything is drawn
+	if fills := drawnFills(); !slices.Equal(fills, []uint32{2, 3, 4, 5}) {
+		t.Errorf("Expected every node drawn, got %v", fills)
+	}
+
+	Resize(800, 600, 1)
+	Update(0)
+	if fills := drawnFills(); !slices.Equal(fills, []uint32{2, 5}) {
+		t.Errorf("Expected only on-screen nodes drawn, got %v", fills)
+	}
+
+	// Within the margin still counts as on screen
+	engine.viewport.Pan(-5000+800+cullMargin-10, 0)
+	Update(0)
+	if fills := drawnFills(); !slices.Contains(fills, 3) || slices.Contains(fills, 2) {
+		t.Errorf("Expected 3 drawn once the camera moved, got %v", fills)
+	}
+}
+
+// boardDocument is a grid of side x side frames with two children each
+func boardDocument(side int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"version":1,"root":{"id":1,"type":"PAGE","children":[`)
+	id := 2
+	for i := 0; i < side*side; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		x, y := (i%side)*150, (i/side)*150
+		fmt.Fprintf(&b, `{"id":%d,"type":"FRAME","style":{"left":%d,"top":%d,"width":100,"height":100},"props":{"fill":%d},"children":[`, id, x, y, 0xFF000000|id)
+		fmt.Fprintf(&b, `{"id":%d,"type":"FRAME","style":{"left":10,"top":10,"width":30,"height":30}},`, id+1)
+		fmt.Fprintf(&b, `{"id":%d,"type":"FRAME","style":{"left":50,"top":50,"width":30,"height":30}}]}`, id+2)
+		id += 3
+	}
+	b.WriteString(`]}}`)
+	return []byte(b.String())
+}
+
+// BenchmarkRender draws a board of 10000 frames, 30000 nodes, with the
+// camera on a corner of it and without knowing the canvas size
+func BenchmarkRender(b *testing.B) {
+	doc := boardDocument(100)
+	for _, bench := range []struct {
+		name   string
+		culled bool
+	}{
+		{"culled", true},
+		{"all", false},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			Init()
+			loadDocument(b, doc)
+			if bench.culled {
+				Resize(1280, 800, 1)
+			}
+			// Too big for one frame budget
+			engine.reconciler.WorkLoop(0)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for b.Loop() {
+				engine.render()
+			}
+			b.ReportMetric(float64(len(engine.commandBuffer.Data)), "words/frame")
+		})
+	}
+}
+
+// BenchmarkUpdate moves one frame of the board, then runs frames of
+// Update until the move is committed and drawn. ns/op is the cost of
+// one edit, frames/edit how many frame budgets it spans.
+func BenchmarkUpdate(b *testing.B) {
+	Init()
+	loadDocument(b, boardDocument(100))
+	Resize(1280, 800, 1)
+	engine.reconciler.WorkLoop(0)
+	node := engine.findNode(2)
+
+	frames := 0
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; b.Loop(); i++ {
+		engine.editNode(node, func() {
+			node.Style.Left = layout.Px(float32(i % 2))
+		})
+		for {
+			Update(1.0 / 60)
+			frames++
+			if engine.reconciler.WipRoot == nil {
+				break
+			}
+		}
+	}
+	b.ReportMetric(float64(frames)/float64(b.N), "frames/edit")
+}
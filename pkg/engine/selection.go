@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"slices"
+	"unsafe"
+
+	"engo/pkg/scene"
+)
+
+// Modes of SelectNode
+const (
+	SELECT_REPLACE int32 = iota
+	SELECT_ADD
+	SELECT_REMOVE
+	SELECT_TOGGLE
+)
+
+// setSelection replaces the selection by nodes in order, leaving out
+// duplicates, the page and nodes no longer in the scene. JS is told
+// through the selection version when the result differs.
+func (e *Engine) setSelection(nodes []*scene.Node) {
+	selection := make([]*scene.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if node == nil || node == e.rootNode || slices.Contains(selection, node) || !e.isAttached(node) {
+			continue
+		}
+		selection = append(selection, node)
+	}
+
+	if slices.Equal(selection, e.selection) {
+		return
+	}
+
+	e.selection = selection
+	e.selectionVersion++
+
+	e.selectionIDs = e.selectionIDs[:0]
+	for _, node := range selection {
+		e.selectionIDs = append(e.selectionIDs, node.ID)
+	}
+}
+
+// SetSelection selects n alone when replace, otherwise adds it last
+func (e *Engine) SetSelection(n *scene.Node, replace bool) {
+	if replace {
+		e.setSelection([]*scene.Node{n})
+		return
+	}
+
+	e.setSelection(append(slices.Clone(e.selection), n))
+}
+
+func (e *Engine) selectNode(node *scene.Node, mode int32) {
+	switch mode {
+	case SELECT_ADD:
+		e.SetSelection(node, false)
+	case SELECT_REMOVE:
+		e.deselect(node)
+	case SELECT_TOGGLE:
+		if slices.Contains(e.selection, node) {
+			e.deselect(node)
+		} else {
+			e.SetSelection(node, false)
+		}
+	default:
+		e.SetSelection(node, true)
+	}
+}
+
+func (e *Engine) deselect(node *scene.Node) {
+	e.setSelection(slices.DeleteFunc(slices.Clone(e.selection), func(n *scene.Node) bool {
+		return n == node
+	}))
+}
+
+// selectable nodes can be picked by the keyboard commands below,
+// like hit-testing they skip hidden and locked nodes
+func selectable(node *scene.Node) bool {
+	return !node.Hidden && !node.Locked
+}
+
+// selectionParent is the parent of the first selected node,
+// the page when nothing is selected
+func (e *Engine) selectionParent() *scene.Node {
+	if len(e.selection) == 0 || e.selection[0].Parent == nil {
+		return e.rootNode
+	}
+	return e.selection[0].Parent
+}
+
+// selectAll selects every sibling of the selection, like Ctrl+A
+func (e *Engine) selectAll() {
+	e.setSelection(slices.DeleteFunc(slices.Clone(e.selectionParent().Children), func(n *scene.Node) bool {
+		return !selectable(n)
+	}))
+}
+
+// selectParent replaces every selected node by its parent (Shift+Enter),
+// nodes right under the page stay selected
+func (e *Engine) selectParent() {
+	parents := make([]*scene.Node, 0, len(e.selection))
+	for _, node := range e.selection {
+		if node.Parent == e.rootNode {
+			parents = append(parents, node)
+		} else {
+			parents = append(parents, node.Parent)
+		}
+	}
+	e.setSelection(parents)
+}
+
+// selectChildren replaces the selection by the children of the selected
+// nodes (Enter), nothing changes if none of them has children
+func (e *Engine) selectChildren() {
+	var children []*scene.Node
+	for _, node := range e.selection {
+		for _, child := range node.Children {
+			if selectable(child) {
+				children = append(children, child)
+			}
+		}
+	}
+
+	if len(children) > 0 {
+		e.setSelection(children)
+	}
+}
+
+// selectSibling moves the selection to the sibling after the last
+// selected node (Tab), before it when reverse (Shift+Tab), wrapping around
+func (e *Engine) selectSibling(reverse bool) {
+	// Nothing selected starts from the first (or last) layer
+	parent := e.rootNode
+	step, from := 1, -1
+	if reverse {
+		step, from = -1, 0
+	}
+
+	// Parent and index both come from the last selected node
+	if len(e.selection) > 0 {
+		last := e.selection[len(e.selection)-1]
+		if last.Parent != nil {
+			parent = last.Parent
+			from = indexOf(parent, last)
+		}
+	}
+
+	siblings := parent.Children
+	if len(siblings) == 0 {
+		return
+	}
+
+	n := len(siblings)
+	for i := 1; i <= n; i++ {
+		sibling := siblings[((from+i*step)%n+n)%n]
+		if selectable(sibling) {
+			e.SetSelection(sibling, true)
+			return
+		}
+	}
+}
+
+func (e *Engine) selectionPtr() uintptr {
+	if len(e.selectionIDs) == 0 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(&e.selectionIDs[0]))
+}
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+	"unsafe"
+)
+
+const selectionDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","children":[
+		{"id":3,"type":"FRAME"},
+		{"id":4,"type":"FRAME","locked":true},
+		{"id":5,"type":"FRAME"}
+	]},
+	{"id":6,"type":"FRAME","hidden":true},
+	{"id":7,"type":"FRAME"}
+]}}`
+
+// exportedSelection reads the IDs JS sees
+func exportedSelection() []uint32 {
+	size := GetSelectionSize()
+	if size == 0 {
+		return nil
+	}
+	if GetSelectionPtr() != uintptr(unsafe.Pointer(&engine.selectionIDs[0])) {
+		panic("selection pointer doesn't match the IDs")
+	}
+	return slices.Clone(engine.selectionIDs[:size])
+}
+
+func TestSelection_Modes(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(selectionDocument))
+	version := GetSelectionVersion()
+
+	SelectNode(3, SELECT_REPLACE)
+	SelectNode(5, SELECT_ADD)
+	SelectNode(3, SELECT_ADD)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{3, 5}) {
+		t.Errorf("Expected [3 5] without duplicate, got %v", ids)
+	}
+	if GetSelectionVersion() == version {
+		t.Errorf("Selection change should bump the version")
+	}
+
+	SelectNode(3, SELECT_TOGGLE)
+	SelectNode(7, SELECT_TOGGLE)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{5, 7}) {
+		t.Errorf("Expected [5 7], got %v", ids)
+	}
+
+	SelectNode(5, SELECT_REMOVE)
+	version = GetSelectionVersion()
+	SelectNode(5, SELECT_REMOVE)
+	if GetSelectionVersion() != version {
+		t.Errorf("No change should keep the version")
+	}
+
+	if SelectNode(1, SELECT_REPLACE) || SelectNode(99, SELECT_REPLACE) {
+		t.Errorf("Page and missing nodes can't be selected")
+	}
+
+	ClearSelection()
+	if GetSelectionSize() != 0 || GetSelectionPtr() != 0 {
+		t.Errorf("Selection should be empty")
+	}
+
+	// Removed nodes leave the selection
+	SelectNode(3, SELECT_REPLACE)
+	SelectNode(7, SELECT_ADD)
+	RemoveNode(2)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{7}) {
+		t.Errorf("Expected [7] after removing 2, got %v", ids)
+	}
+}
+
+func TestSelection_Navigation(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(selectionDocument))
+
+	// Hidden 6 is skipped
+	SelectAll()
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{2, 7}) {
+		t.Errorf("Expected [2 7], got %v", ids)
+	}
+
+	SelectNode(2, SELECT_REPLACE)
+	SelectChildren()
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{3, 5}) {
+		t.Errorf("Expected unlocked children [3 5], got %v", ids)
+	}
+
+	SelectParent()
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{2}) {
+		t.Errorf("Expected parent [2], got %v", ids)
+	}
+	SelectParent()
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{2}) {
+		t.Errorf("Top level node should stay, got %v", ids)
+	}
+
+	SelectSibling(false)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{7}) {
+		t.Errorf("Tab should skip hidden 6, got %v", ids)
+	}
+	SelectSibling(false)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{2}) {
+		t.Errorf("Tab should wrap around, got %v", ids)
+	}
+
+	SelectNode(5, SELECT_REPLACE)
+	SelectSibling(true)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{3}) {
+		t.Errorf("Shift+Tab should skip locked 4, got %v", ids)
+	}
+
+	ClearSelection()
+	SelectSibling(true)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{7}) {
+		t.Errorf("Shift+Tab from nothing should pick the last layer, got %v", ids)
+	}
+	ClearSelection()
+	SelectSibling(false)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{2}) {
+		t.Errorf("Tab from nothing should pick the first layer, got %v", ids)
+	}
+
+	// Selection across parents goes on from the last selected node
+	SelectNode(7, SELECT_REPLACE)
+	SelectNode(3, SELECT_ADD)
+	SelectSibling(false)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{5}) {
+		t.Errorf("Tab should move inside frame 2, got %v", ids)
+	}
+	SelectNode(3, SELECT_REPLACE)
+	SelectNode(7, SELECT_ADD)
+	SelectSibling(true)
+	if ids := exportedSelection(); !slices.Equal(ids, []uint32{2}) {
+		t.Errorf("Shift+Tab should move inside the page, got %v", ids)
+	}
+}
@@ -0,0 +1,390 @@
+package engine
+
+import (
+	"math"
+	"slices"
+	"strconv"
+
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+	"engo/pkg/scene"
+)
+
+// Sizes in screen pixel, the same at any zoom
+const (
+	// Edges closer than this to a target snap to it
+	snapThreshold = 6
+	// How far around the dragged box siblings are looked up
+	snapReach = 2000
+	// Label of a distance
+	labelHeight    = 16
+	labelCharWidth = 7
+	labelPadding   = 4
+	labelFontSize  = 11
+)
+
+// Labels use the UI font, not whatever the scene set last
+const labelFont = "sans-serif"
+
+// Positions snap to whole pixels when nothing else is close
+const pixelGrid = 1
+
+// Below this two edges are aligned
+const snapEpsilon = 1e-3
+
+var guideColor = protocol.Color(242, 72, 34, 255)
+
+// guide is a line in world space, labeled with the distance it
+// measures if label > 0
+type guide struct {
+	x0, y0, x1, y1 float32
+	label          float32
+}
+
+// snapTargets is what the dragged box snaps to: siblings of the
+// selection around it and the frame they are in
+type snapTargets struct {
+	rects  []rtree.Rect
+	parent rtree.Rect
+	// Pages have no edges to snap to
+	hasParent bool
+}
+
+// findSnapTargets looks the siblings of the selection up once per drag,
+// only the selection moves while dragging
+func (e *Engine) findSnapTargets(bounds rtree.Rect) snapTargets {
+	var targets snapTargets
+	if len(e.selection) == 0 {
+		return targets
+	}
+
+	parent := e.selection[0].Parent
+	if parent != nil && parent.Type != scene.Page {
+		targets.parent, targets.hasParent = parent.LastWorldMBR, true
+	}
+
+	reach := float64(snapReach / e.viewScale())
+	area := rtree.Rect{
+		MinX: bounds.MinX - reach, MinY: bounds.MinY - reach,
+		MaxX: bounds.MaxX + reach, MaxY: bounds.MaxY + reach,
+	}
+	for _, data := range e.spatial.Search(area) {
+		node := e.findNode(data.(uint32))
+		if node == nil || node.Parent != parent || node.Hidden || slices.Contains(e.selection, node) {
+			continue
+		}
+		targets.rects = append(targets.rects, node.LastWorldMBR)
+	}
+
+	// Search order depends on the tree, guides should not
+	slices.SortFunc(targets.rects, func(a, b rtree.Rect) int {
+		if a.MinX != b.MinX {
+			return cmpFloat(a.MinX, b.MinX)
+		}
+		return cmpFloat(a.MinY, b.MinY)
+	})
+	return targets
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// span is a box along one axis
+type span struct{ min, max float64 }
+
+func (s span) mid() float64 { return (s.min + s.max) / 2 }
+
+// axis picks x or y of rects so both axes share the code
+type axis bool
+
+const (
+	axisX axis = false
+	axisY axis = true
+)
+
+func (a axis) along(r rtree.Rect) span {
+	if a == axisY {
+		return span{r.MinY, r.MaxY}
+	}
+	return span{r.MinX, r.MaxX}
+}
+
+func (a axis) across(r rtree.Rect) span {
+	return (!a).along(r)
+}
+
+func overlaps(a, b span) bool {
+	return a.min < b.max && b.min < a.max
+}
+
+// snapBox returns how far box moves along a to the closest target
+// within threshold: an edge or center of a sibling or the parent, or
+// the gap that spaces it equally between siblings. Without one it
+// moves to the pixel grid.
+func (t *snapTargets) snapBox(box rtree.Rect, a axis, threshold float64) float64 {
+	b := a.along(box)
+	best, offset, found := threshold, 0.0, false
+	try := func(source, target float64) {
+		if d := target - source; math.Abs(d) <= best {
+			best, offset, found = math.Abs(d), d, true
+		}
+	}
+	align := func(r span) {
+		for _, target := range [3]float64{r.min, r.mid(), r.max} {
+			try(b.min, target)
+			try(b.mid(), target)
+			try(b.max, target)
+		}
+	}
+
+	for _, r := range t.rects {
+		align(a.along(r))
+	}
+	if t.hasParent {
+		align(a.along(t.parent))
+	}
+
+	row := t.row(box, a)
+	before, after, hasBefore, hasAfter := neighbors(row, b)
+	if hasBefore && hasAfter {
+		try(b.min, (before.max+after.min-(b.max-b.min))/2)
+	}
+	for _, gap := range gaps(row) {
+		if hasBefore {
+			try(b.min, before.max+gap)
+		}
+		if hasAfter {
+			try(b.max, after.min-gap)
+		}
+	}
+
+	if found {
+		return offset
+	}
+	return math.Round(b.min/pixelGrid)*pixelGrid - b.min
+}
+
+// snapEdge returns how far the edge at v moves along a to the closest
+// edge or center of a sibling or the parent, or to the pixel grid
+func (t *snapTargets) snapEdge(v float64, a axis, threshold float64) float64 {
+	best, offset, found := threshold, 0.0, false
+	align := func(r span) {
+		for _, target := range [3]float64{r.min, r.mid(), r.max} {
+			if d := target - v; math.Abs(d) <= best {
+				best, offset, found = math.Abs(d), d, true
+			}
+		}
+	}
+
+	for _, r := range t.rects {
+		align(a.along(r))
+	}
+	if t.hasParent {
+		align(a.along(t.parent))
+	}
+
+	if found {
+		return offset
+	}
+	return math.Round(v/pixelGrid)*pixelGrid - v
+}
+
+// row returns the siblings beside box along a, sorted
+func (t *snapTargets) row(box rtree.Rect, a axis) []span {
+	var row []span
+	for _, r := range t.rects {
+		if overlaps(a.across(r), a.across(box)) {
+			row = append(row, a.along(r))
+		}
+	}
+	slices.SortFunc(row, func(x, y span) int { return cmpFloat(x.min, y.min) })
+	return row
+}
+
+// neighbors returns the closest spans of row before and after b
+func neighbors(row []span, b span) (before, after span, hasBefore, hasAfter bool) {
+	for _, s := range row {
+		if s.max <= b.min+snapEpsilon && (!hasBefore || s.max > before.max) {
+			before, hasBefore = s, true
+		}
+		if s.min >= b.max-snapEpsilon && (!hasAfter || s.min < after.min) {
+			after, hasAfter = s, true
+		}
+	}
+	return
+}
+
+// gaps returns the space between consecutive spans of a sorted row
+func gaps(row []span) []float64 {
+	var gaps []float64
+	for i := 1; i < len(row); i++ {
+		if gap := row[i].min - row[i-1].max; gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+	return gaps
+}
+
+// guidesFor lists the guides of box at its snapped position: lines
+// through edges and centers it shares with siblings or the parent,
+// labeled with the distance to the closest, and the gaps it spaces
+// equally
+func (t *snapTargets) guidesFor(box rtree.Rect, spacing bool) []guide {
+	var guides []guide
+	for _, a := range [2]axis{axisX, axisY} {
+		guides = t.alignGuides(guides, box, a)
+		if spacing {
+			guides = t.spacingGuides(guides, box, a)
+		}
+	}
+	return guides
+}
+
+func (t *snapTargets) alignGuides(guides []guide, box rtree.Rect, a axis) []guide {
+	b, across := a.along(box), a.across(box)
+	rects := t.rects
+	if t.hasParent {
+		rects = append(slices.Clip(rects), t.parent)
+	}
+
+	for _, v := range [3]float64{b.min, b.mid(), b.max} {
+		line, gap, aligned := across, math.Inf(1), false
+		var nearest span
+		for _, r := range rects {
+			s := a.along(r)
+			if math.Abs(s.min-v) > snapEpsilon && math.Abs(s.mid()-v) > snapEpsilon && math.Abs(s.max-v) > snapEpsilon {
+				continue
+			}
+
+			other := a.across(r)
+			line.min, line.max = min(line.min, other.min), max(line.max, other.max)
+			aligned = true
+			if d := distance(across, other); d > 0 && d < gap {
+				gap, nearest = d, other
+			}
+		}
+		if !aligned {
+			continue
+		}
+
+		guides = append(guides, a.guide(v, line, 0))
+		if !math.IsInf(gap, 1) {
+			from, to := across.max, nearest.min
+			if nearest.max <= across.min {
+				from, to = nearest.max, across.min
+			}
+			guides = append(guides, a.guide(v, span{from, to}, float32(gap)))
+		}
+	}
+	return guides
+}
+
+func (t *snapTargets) spacingGuides(guides []guide, box rtree.Rect, a axis) []guide {
+	b := a.along(box)
+	row := t.row(box, a)
+	before, after, hasBefore, hasAfter := neighbors(row, b)
+
+	// Gaps beside the box count when another gap of the row matches
+	var beside []span
+	if hasBefore {
+		beside = append(beside, span{before.max, b.min})
+	}
+	if hasAfter {
+		beside = append(beside, span{b.max, after.min})
+	}
+
+	var others []span
+	for i := 1; i < len(row); i++ {
+		if row[i].min > row[i-1].max {
+			others = append(others, span{row[i-1].max, row[i].min})
+		}
+	}
+
+	// Each equal gap is labeled once, even if it matches both sides
+	var equal []span
+	for _, g := range beside {
+		size := g.max - g.min
+		if size <= 0 {
+			continue
+		}
+		for _, o := range slices.Concat(beside, others) {
+			if o == g || math.Abs((o.max-o.min)-size) > snapEpsilon {
+				continue
+			}
+			for _, s := range [2]span{g, o} {
+				if !slices.Contains(equal, s) {
+					equal = append(equal, s)
+				}
+			}
+		}
+	}
+
+	at := a.across(box).mid()
+	for _, s := range equal {
+		guides = append(guides, a.crossGuide(s, at, float32(s.max-s.min)))
+	}
+	return guides
+}
+
+// distance returns the space between two spans, 0 if they overlap
+func distance(a, b span) float64 {
+	if a.max <= b.min {
+		return b.min - a.max
+	}
+	if b.max <= a.min {
+		return a.min - b.max
+	}
+	return 0
+}
+
+// guide is a line at v along a, spanning s across
+func (a axis) guide(v float64, s span, label float32) guide {
+	if a == axisY {
+		return guide{float32(s.min), float32(v), float32(s.max), float32(v), label}
+	}
+	return guide{float32(v), float32(s.min), float32(v), float32(s.max), label}
+}
+
+// crossGuide is a line covering s along a, at across
+func (a axis) crossGuide(s span, at float64, label float32) guide {
+	return (!a).guide(at, s, label)
+}
+
+// drawGuides draws the guides of the drag in screen space so lines stay
+// one pixel wide and labels keep their size at any zoom
+func (e *Engine) drawGuides() {
+	if len(e.guides) == 0 {
+		return
+	}
+
+	cb := e.commandBuffer
+	cb.WriteSetMatrix(protocol.IdentityMatrix())
+	cb.WriteSetStroke(guideColor, 1)
+	for _, g := range e.guides {
+		x0, y0 := e.viewport.WorldToScreen(g.x0, g.y0)
+		x1, y1 := e.viewport.WorldToScreen(g.x1, g.y1)
+		cb.WriteDrawLine(x0, y0, x1, y1)
+	}
+
+	cb.WriteSetFont(labelFontSize, 0, labelFont)
+	for _, g := range e.guides {
+		if g.label <= 0 {
+			continue
+		}
+
+		x, y := e.viewport.WorldToScreen((g.x0+g.x1)/2, (g.y0+g.y1)/2)
+		text := strconv.FormatFloat(math.Round(float64(g.label)*100)/100, 'f', -1, 32)
+		w := float32(len(text))*labelCharWidth + 2*labelPadding
+		cb.WriteSetFill(guideColor)
+		cb.WriteDrawRect(x-w/2, y-labelHeight/2, w, labelHeight, protocol.DrawFill)
+		cb.WriteSetFill(handleFill)
+		cb.WriteDrawText(x-w/2+labelPadding, y+labelHeight/2-labelPadding, text)
+	}
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"engo/internal/protocol"
+)
+
+// A and B are 100 apart, C is dragged next to them
+const snapDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":0,"top":0,"width":100,"height":100}},
+	{"id":3,"type":"FRAME","style":{"left":200,"top":0,"width":100,"height":100}},
+	{"id":4,"type":"FRAME","style":{"left":500,"top":20,"width":50,"height":40}}
+]}}`
+
+// commands returns the payload of every op of the command buffer
+func commands(op protocol.OpCode) [][]uint32 {
+	var found [][]uint32
+	data := engine.commandBuffer.Data
+	for i := 0; i < len(data); {
+		size := int(data[i] >> 8)
+		if protocol.OpCode(data[i]&0xFF) == op {
+			found = append(found, data[i+1:i+1+size])
+		}
+		i += size + 1
+	}
+	return found
+}
+
+// labels decodes the text drawn into the command buffer
+func labels() []string {
+	var texts []string
+	for _, payload := range commands(protocol.OpDrawText) {
+		b := make([]byte, payload[2])
+		for i := range b {
+			b[i] = byte(payload[3+i/4] >> (8 * (i % 4)))
+		}
+		texts = append(texts, string(b))
+	}
+	return texts
+}
+
+func TestSnap_Move(t *testing.T) {
+	cases := []struct {
+		name      string
+		dx, dy    float32
+		modifiers uint32
+		left, top float32
+		guides    bool
+	}{
+		{"sibling edge", -198, 0, 0, 300, 20, true},
+		{"equal spacing", -97, 0, 0, 400, 20, true},
+		{"pixel grid", -50.4, 0.3, 0, 450, 20, false},
+		{"turned off", -97, 0, protocol.ModCtrl, 403, 20, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			Init()
+			loadDocument(t, []byte(snapDocument))
+			Update(0)
+
+			drag(525, 40, 525+c.dx, 40+c.dy, c.modifiers)
+			expectBox(t, 4, c.left, c.top, 50, 40)
+			if got := len(commands(protocol.OpDrawLine)) > 0; got != c.guides {
+				t.Errorf("Expected guides %v, got %v", c.guides, got)
+			}
+
+			release(525+c.dx, 40+c.dy, c.modifiers)
+			if len(engine.guides) != 0 {
+				t.Errorf("Guides should go away with the drag")
+			}
+		})
+	}
+}
+
+func TestSnap_Spacing(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(snapDocument))
+	Update(0)
+
+	drag(525, 40, 428, 40, 0)
+	gaps := 0
+	for _, text := range labels() {
+		if text == "100" {
+			gaps++
+		}
+	}
+	if gaps != 2 {
+		t.Errorf("Expected both gaps of 100 labeled, got %v", labels())
+	}
+
+	// Labels set their own font
+	fonts := commands(protocol.OpSetFont)
+	if len(fonts) == 0 {
+		t.Fatalf("Labels should set the UI font")
+	}
+	font := fonts[len(fonts)-1]
+	if math.Float32frombits(font[0]) != labelFontSize || font[2] != uint32(len(labelFont)) {
+		t.Errorf("Expected %vpx %s, got %v", labelFontSize, labelFont, font)
+	}
+}
+
+func TestSnap_Zoom(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(snapDocument))
+	engine.viewport.Scale = 2
+	Update(0)
+
+	// 4 world pixel from B is 8 screen pixel, too far to snap
+	drag(1050, 80, 1050-2*296, 80, 0)
+	expectBox(t, 4, 204, 20, 50, 40)
+
+	// 2 world pixel is close enough
+	pointerTo(1050-2*298, 80, protocol.ButtonLeft, 0)
+	Update(0)
+	expectBox(t, 4, 200, 20, 50, 40)
+}
+
+func TestSnap_Resize(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(snapDocument))
+	engine.SetSelection(engine.findNode(2), true)
+	Update(0)
+
+	// Right edge of A snaps to the left of B
+	drag(100, 50, 197, 50, 0)
+	expectBox(t, 2, 0, 0, 200, 100)
+	if len(engine.guides) == 0 {
+		t.Errorf("Snapped edge should show a guide")
+	}
+
+	// Guides are drawn in screen space
+	for _, payload := range commands(protocol.OpDrawLine) {
+		x := math.Float32frombits(payload[0])
+		if x != math.Float32frombits(payload[2]) {
+			continue
+		}
+		if x != 200 && x != 0 && x != 100 && x != 300 && x != 250 && x != 50 {
+			t.Errorf("Unexpected vertical guide at %v", x)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"math"
+
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+	"engo/pkg/scene"
+)
+
+// indexSubtree inserts node and its descendants into the R-tree
+// with their world bounds
+func (e *Engine) indexSubtree(node *scene.Node, parentMatrix protocol.Matrix) {
+	world := parentMatrix.Multiply(node.GetLocalMatrix())
+
+	// Page is infinite canvas, it has no bounds to index
+	if node.Type != scene.Page {
+		e.insertSpatial(node, world)
+	}
+
+	for _, child := range node.Children {
+		e.indexSubtree(child, world)
+	}
+}
+
+func (e *Engine) insertSpatial(node *scene.Node, world protocol.Matrix) {
+	_, _, w, h := node.Box()
+
+	node.LastWorldMBR = worldBounds(world, w, h)
+	e.spatial.Insert(node.LastWorldMBR, node.ID)
+}
+
+// worldBounds returns the axis-aligned box around a w x h box placed
+// by world, rotated boxes take the bounds of their four corners
+func worldBounds(world protocol.Matrix, w, h float32) rtree.Rect {
+	x, y := world.Apply(0, 0)
+	r := rtree.Rect{MinX: float64(x), MinY: float64(y), MaxX: float64(x), MaxY: float64(y)}
+
+	for _, corner := range [3][2]float32{{w, 0}, {w, h}, {0, h}} {
+		x, y := world.Apply(corner[0], corner[1])
+		r.MinX, r.MaxX = math.Min(r.MinX, float64(x)), math.Max(r.MaxX, float64(x))
+		r.MinY, r.MaxY = math.Min(r.MinY, float64(y)), math.Max(r.MaxY, float64(y))
+	}
+	return r
+}
+
+// RemoveSpatial implements fiber.Host
+func (e *Engine) RemoveSpatial(node *scene.Node) {
+	e.spatial.Delete(node.LastWorldMBR, node.ID)
+}
+
+// RemoveDOMOverlay implements fiber.Host
+// TODO: Notify JS to remove the DOM element overlay of INPUT node
+func (e *Engine) RemoveDOMOverlay(id uint32) {}
+
+// worldMatrix returns the transform from node space to world space
+func worldMatrix(node *scene.Node) protocol.Matrix {
+	if node == nil {
+		return protocol.IdentityMatrix()
+	}
+
+	return worldMatrix(node.Parent).Multiply(node.GetLocalMatrix())
+}
+
+// removeSubtreeSpatial removes node and its descendants from the R-tree
+func (e *Engine) removeSubtreeSpatial(node *scene.Node) {
+	if node.Type != scene.Page {
+		e.RemoveSpatial(node)
+	}
+
+	for _, child := range node.Children {
+		e.removeSubtreeSpatial(child)
+	}
+}
+
+// reindexSubtree refreshes world bounds of node and its descendants,
+// after node moved or resized. Detached nodes are left out.
+func (e *Engine) reindexSubtree(node *scene.Node) {
+	e.removeSubtreeSpatial(node)
+
+	if e.isAttached(node) {
+		e.indexSubtree(node, worldMatrix(node.Parent))
+	}
+}
+
+// isAttached reports whether node belongs to the current scene graph
+func (e *Engine) isAttached(node *scene.Node) bool {
+	for node.Parent != nil {
+		node = node.Parent
+	}
+
+	return node == e.rootNode
+}
+
+// UpdateSpatial implements fiber.Host
+func (e *Engine) UpdateSpatial(node *scene.Node) {
+	if node.Type == scene.Page || !e.isAttached(node) {
+		return
+	}
+
+	e.RemoveSpatial(node)
+	e.insertSpatial(node, worldMatrix(node))
+}
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"unsafe"
+
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+	"engo/pkg/text"
+)
+
+func (e *Engine) allocFontBuffer(size int) uintptr {
+	if cap(e.fontBuffer) < size {
+		e.fontBuffer = make([]uint32, size)
+	}
+	e.fontBuffer = e.fontBuffer[:size]
+
+	if size == 0 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(&e.fontBuffer[0]))
+}
+
+// registerFont parses metrics and lays every text node out again,
+// they may have been measured with another font or the fallback
+func (e *Engine) registerFont(id uint32, metrics []uint32) bool {
+	font, err := text.ParseFont(metrics)
+	if err != nil {
+		return false
+	}
+	e.fonts.Register(id, font)
+
+	for _, node := range e.nodes {
+		if node.Type == scene.Text {
+			node.MarkDirty(scene.FlagLayoutDirty)
+		}
+	}
+	e.reconciler.ScheduleUpdate(e.rootNode)
+	return true
+}
+
+// Measure implements fiber.Host, text with an auto size is as large
+// as its content broken at the available width
+func (e *Engine) Measure(node *scene.Node) layout.MeasureFunc {
+	props, ok := node.Props.(*scene.TextProps)
+	if !ok {
+		return nil
+	}
+
+	font := e.fonts.Lookup(props.FontFamily)
+	s := text.Style{
+		Size:          props.FontSize,
+		LineHeight:    props.LineHeight,
+		LetterSpacing: props.LetterSpacing,
+	}
+	return func(availableWidth, availableHeight float32) (float32, float32) {
+		return font.Measure(props.Content, s, availableWidth)
+	}
+}
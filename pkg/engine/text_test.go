@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+const textDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":0,"top":0,"width":100,"height":100},"children":[
+		{"id":3,"type":"TEXT","style":{"left":0,"top":0},"props":{"content":"hello world","fontFamily":"Mono","fontSize":20}}
+	]}
+]}}`
+
+// registerTestFont registers "Mono": every rune 10px wide and
+// lines 20px tall at size 20
+func registerTestFont(t *testing.T) {
+	t.Helper()
+
+	metrics := []uint32{
+		1000,
+		math.Float32bits(800),
+		math.Float32bits(200),
+		0,
+		math.Float32bits(500),
+		0, 0,
+		4, 'M' | 'o'<<8 | 'n'<<16 | 'o'<<24,
+	}
+	AllocFontBuffer(int32(len(metrics)))
+	copy(engine.fontBuffer, metrics)
+
+	if !RegisterFont(1, int32(len(metrics))) {
+		t.Fatalf("RegisterFont rejected metrics")
+	}
+}
+
+func TestText_Measure(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(textDocument))
+	text := engine.findNode(3)
+
+	// Fallback metrics until the font arrives
+	engine.reconciler.WorkLoop(0)
+	if _, _, w, _ := text.Box(); w == 0 {
+		t.Errorf("Text should be measured with the fallback font")
+	}
+
+	registerTestFont(t)
+	engine.reconciler.WorkLoop(0)
+
+	// 110px of text wraps in the 100px frame
+	if _, _, w, h := text.Box(); w != 50 || h != 40 {
+		t.Errorf("Expected 50x40 after wrapping, got %vx%v", w, h)
+	}
+
+	if RegisterFont(2, int32(len(engine.fontBuffer)+1)) {
+		t.Errorf("Size larger than the buffer should be rejected")
+	}
+}
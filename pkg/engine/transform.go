@@ -0,0 +1,467 @@
+package engine
+
+import (
+	"math"
+
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+)
+
+// Handles of the selection box, edges it moves when dragged
+const (
+	handleLeft uint8 = 1 << iota
+	handleRight
+	handleTop
+	handleBottom
+	// Just outside a corner, rotates instead of resizing
+	handleRotate
+)
+
+// Corners first, they win over edges on small boxes
+var resizeHandles = [8]uint8{
+	handleLeft | handleTop, handleRight | handleTop,
+	handleRight | handleBottom, handleLeft | handleBottom,
+	handleTop, handleRight, handleBottom, handleLeft,
+}
+
+// Sizes in screen pixel, the same at any zoom
+const (
+	handleSize = 8
+	// How far outside a corner the rotate zone reaches
+	rotateReach = 16
+	// Rotation snaps to this many degrees
+	rotateSnap = 15
+)
+
+var handleFill = protocol.Color(255, 255, 255, 255)
+
+type dragKind uint8
+
+const (
+	dragNone dragKind = iota
+	dragMove
+	dragResize
+	dragRotate
+)
+
+// dragStart is a selected node as it was when the drag began
+type dragStart struct {
+	node       *scene.Node
+	w, h       float32
+	rotation   float32
+	cx, cy     float32 // World center
+	parent     protocol.Matrix
+	freeLayout bool
+}
+
+// transformDrag moves, resizes or rotates the selection. Every frame
+// places the nodes again from where they started, so nothing drifts.
+type transformDrag struct {
+	kind   dragKind
+	handle uint8
+	moved  bool
+
+	startX, startY float32
+	// Selection box and its box to world matrix when the drag began
+	w, h  float32
+	frame protocol.Matrix
+	nodes []dragStart
+	// World bounds of the selection and what it snaps to
+	bounds  rtree.Rect
+	targets snapTargets
+	// Deselected if the drag ends without moving
+	toggle *scene.Node
+}
+
+// selectionFrame returns the size of the box around the selection and
+// the matrix from box to world space. A single node keeps its rotation,
+// several nodes share their axis-aligned world bounds.
+func (e *Engine) selectionFrame() (w, h float32, frame protocol.Matrix, ok bool) {
+	switch len(e.selection) {
+	case 0:
+		return 0, 0, protocol.Matrix{}, false
+	case 1:
+		node := e.selection[0]
+		_, _, w, h = node.Box()
+		return w, h, worldMatrix(node), true
+	}
+
+	bounds := e.selectionBounds()
+	frame = protocol.TranslateMatrix(float32(bounds.MinX), float32(bounds.MinY))
+	return float32(bounds.MaxX - bounds.MinX), float32(bounds.MaxY - bounds.MinY), frame, true
+}
+
+// selectionBounds returns the world bounds around the selection
+func (e *Engine) selectionBounds() rtree.Rect {
+	bounds := e.selection[0].LastWorldMBR
+	for _, node := range e.selection[1:] {
+		bounds = rtree.Union(bounds, node.LastWorldMBR)
+	}
+	return bounds
+}
+
+// handlePosition is the point of handle on a w x h box
+func handlePosition(handle uint8, w, h float32) (float32, float32) {
+	x, y := w/2, h/2
+	if handle&handleLeft != 0 {
+		x = 0
+	} else if handle&handleRight != 0 {
+		x = w
+	}
+	if handle&handleTop != 0 {
+		y = 0
+	} else if handle&handleBottom != 0 {
+		y = h
+	}
+	return x, y
+}
+
+// handleAt returns the handle of the selection box under world point
+// (x, y), 0 if none
+func (e *Engine) handleAt(x, y float32) uint8 {
+	w, h, frame, ok := e.selectionFrame()
+	if !ok {
+		return 0
+	}
+	inverse, ok := frame.Invert()
+	if !ok {
+		return 0
+	}
+
+	// Box space has the scale of world space
+	bx, by := inverse.Apply(x, y)
+	reach := handleSize / e.viewScale()
+	for _, handle := range resizeHandles {
+		hx, hy := handlePosition(handle, w, h)
+		if abs(bx-hx) <= reach && abs(by-hy) <= reach {
+			return handle
+		}
+	}
+
+	if inBox(bx, by, w, h) {
+		return 0
+	}
+	rotate := rotateReach / e.viewScale()
+	for _, corner := range resizeHandles[:4] {
+		hx, hy := handlePosition(corner, w, h)
+		if abs(bx-hx) <= rotate && abs(by-hy) <= rotate {
+			return handleRotate | corner
+		}
+	}
+	return 0
+}
+
+// startTransform begins dragging the selection from world point (x, y)
+func (e *Engine) startTransform(kind dragKind, handle uint8, x, y float32) {
+	w, h, frame, ok := e.selectionFrame()
+	if !ok {
+		return
+	}
+
+	d := transformDrag{
+		kind: kind, handle: handle,
+		startX: x, startY: y,
+		w: w, h: h, frame: frame,
+	}
+	for _, node := range e.selection {
+		world := worldMatrix(node)
+		local, ok := node.GetLocalMatrix().Invert()
+		if !ok || node.Style == nil {
+			continue
+		}
+
+		_, _, nw, nh := node.Box()
+		cx, cy := world.Apply(nw/2, nh/2)
+		d.nodes = append(d.nodes, dragStart{
+			node: node,
+			w:    nw, h: nh,
+			rotation: node.Style.Rotation,
+			cx:       cx, cy: cy,
+			parent:     world.Multiply(local),
+			freeLayout: node.Parent.Style == nil || node.Parent.Style.Layout == layout.ModeNone,
+		})
+	}
+
+	if kind != dragRotate {
+		d.bounds = e.selectionBounds()
+		d.targets = e.findSnapTargets(d.bounds)
+	}
+
+	// The whole drag is one undo step
+	e.history.begin()
+	e.transform = d
+}
+
+// dragTransform follows the pointer of this frame
+func (e *Engine) dragTransform() {
+	d := &e.transform
+	if d.kind == dragNone {
+		return
+	}
+
+	x, y := e.screenToWorld(e.input.X, e.input.Y)
+	if !d.moved {
+		dist := math.Hypot(float64(x-d.startX), float64(y-d.startY))
+		d.moved = float32(dist)*e.viewScale() >= dragThreshold
+	}
+
+	if d.moved {
+		modifiers := e.input.Modifiers
+		switch d.kind {
+		case dragMove:
+			e.dragMove(x, y, modifiers)
+		case dragResize:
+			e.dragResize(x, y, modifiers)
+		case dragRotate:
+			e.dragRotate(x, y, modifiers)
+		}
+	}
+
+	if e.input.Released&protocol.ButtonLeft != 0 {
+		e.endTransform()
+	}
+}
+
+func (e *Engine) endTransform() {
+	if e.transform.kind == dragNone {
+		return
+	}
+
+	d := e.transform
+	e.transform = transformDrag{}
+	e.guides = nil
+	e.history.commit()
+	if d.toggle != nil && !d.moved {
+		e.deselect(d.toggle)
+	}
+}
+
+// snaps says whether drags snap, Ctrl or Cmd turns it off
+func snaps(modifiers uint32) bool {
+	return modifiers&(protocol.ModCtrl|protocol.ModMeta) == 0
+}
+
+// dragMove moves the selection by the pointer offset, Shift keeps it on
+// the dominant axis
+func (e *Engine) dragMove(x, y float32, modifiers uint32) {
+	d := &e.transform
+	dx, dy := x-d.startX, y-d.startY
+	lockX, lockY := false, false
+	if modifiers&protocol.ModShift != 0 {
+		if abs(dx) > abs(dy) {
+			dy, lockY = 0, true
+		} else {
+			dx, lockX = 0, true
+		}
+	}
+
+	e.guides = nil
+	if snaps(modifiers) {
+		threshold := float64(snapThreshold / e.viewScale())
+		box := offsetRect(d.bounds, dx, dy)
+		if !lockX {
+			dx += float32(d.targets.snapBox(box, axisX, threshold))
+		}
+		if !lockY {
+			dy += float32(d.targets.snapBox(box, axisY, threshold))
+		}
+		e.guides = d.targets.guidesFor(offsetRect(d.bounds, dx, dy), true)
+	}
+
+	for _, s := range d.nodes {
+		e.place(s, s.cx+dx, s.cy+dy, s.w, s.h, s.rotation)
+	}
+}
+
+// dragResize moves the edges of the handle to the pointer. Shift keeps
+// the aspect ratio, Alt resizes from the center.
+func (e *Engine) dragResize(x, y float32, modifiers uint32) {
+	d := &e.transform
+	inverse, ok := d.frame.Invert()
+	if !ok || d.w <= 0 || d.h <= 0 {
+		return
+	}
+
+	px, py := inverse.Apply(x, y)
+	sx0, sy0 := inverse.Apply(d.startX, d.startY)
+	dx, dy := px-sx0, py-sy0
+
+	fromCenter := modifiers&protocol.ModAlt != 0
+	horizontal := d.handle&(handleLeft|handleRight) != 0
+	vertical := d.handle&(handleTop|handleBottom) != 0
+
+	// Dragged edges snap while the box is not rotated
+	f := d.frame
+	upright := f[0] == 1 && f[1] == 0 && f[2] == 0 && f[3] == 1
+	snap := upright && snaps(modifiers)
+	if snap {
+		threshold := float64(snapThreshold / e.viewScale())
+		if horizontal {
+			edge := f[4] + dx
+			if d.handle&handleRight != 0 {
+				edge += d.w
+			}
+			dx += float32(d.targets.snapEdge(float64(edge), axisX, threshold))
+		}
+		if vertical {
+			edge := f[5] + dy
+			if d.handle&handleBottom != 0 {
+				edge += d.h
+			}
+			dy += float32(d.targets.snapEdge(float64(edge), axisY, threshold))
+		}
+	}
+
+	// Scale of each axis around its anchor: the opposite edge,
+	// or the center
+	scale := func(start, delta float32, near bool) (float32, float32) {
+		if !near {
+			delta = -delta
+		}
+		if fromCenter {
+			return start / 2, (start + 2*delta) / start
+		}
+		if near {
+			return 0, (start + delta) / start
+		}
+		return start, (start + delta) / start
+	}
+
+	ax, sx := d.w/2, float32(1)
+	if horizontal {
+		ax, sx = scale(d.w, dx, d.handle&handleRight != 0)
+	}
+	ay, sy := d.h/2, float32(1)
+	if vertical {
+		ay, sy = scale(d.h, dy, d.handle&handleBottom != 0)
+	}
+
+	if modifiers&protocol.ModShift != 0 {
+		switch {
+		case horizontal && vertical:
+			s := max(abs(sx), abs(sy))
+			sx, sy = float32(math.Copysign(float64(s), float64(sx))), float32(math.Copysign(float64(s), float64(sy)))
+		case horizontal:
+			sy = abs(sx)
+		case vertical:
+			sx = abs(sy)
+		}
+	}
+
+	e.guides = nil
+	if snap {
+		x0, x1 := ax-ax*sx, ax+(d.w-ax)*sx
+		y0, y1 := ay-ay*sy, ay+(d.h-ay)*sy
+		box := rtree.Rect{
+			MinX: float64(f[4] + min(x0, x1)), MinY: float64(f[5] + min(y0, y1)),
+			MaxX: float64(f[4] + max(x0, x1)), MaxY: float64(f[5] + max(y0, y1)),
+		}
+		e.guides = d.targets.guidesFor(box, false)
+	}
+
+	// Flipped past the anchor, the box mirrors around it
+	for _, s := range d.nodes {
+		cx, cy := inverse.Apply(s.cx, s.cy)
+		wx, wy := d.frame.Apply(ax+(cx-ax)*sx, ay+(cy-ay)*sy)
+		e.place(s, wx, wy, max(1, s.w*abs(sx)), max(1, s.h*abs(sy)), s.rotation)
+	}
+}
+
+// dragRotate turns the selection around its center by the angle the
+// pointer swept, snapped to rotateSnap degrees unless Ctrl or Cmd is held
+func (e *Engine) dragRotate(x, y float32, modifiers uint32) {
+	d := &e.transform
+	cx, cy := d.frame.Apply(d.w/2, d.h/2)
+
+	start := math.Atan2(float64(d.startY-cy), float64(d.startX-cx))
+	now := math.Atan2(float64(y-cy), float64(x-cx))
+	delta := (now - start) * 180 / math.Pi
+
+	if snaps(modifiers) {
+		if len(d.nodes) == 1 {
+			// A single node snaps its own angle, not the swept one
+			rotation := float64(d.nodes[0].rotation)
+			delta = snapAngle(rotation+delta) - rotation
+		} else {
+			delta = snapAngle(delta)
+		}
+	}
+
+	rotate := protocol.RotateMatrix(delta * math.Pi / 180)
+	for _, s := range d.nodes {
+		ox, oy := rotate.Apply(s.cx-cx, s.cy-cy)
+		e.place(s, cx+ox, cy+oy, s.w, s.h, normalizeAngle(s.rotation+float32(delta)))
+	}
+}
+
+func snapAngle(deg float64) float64 {
+	return math.Round(deg/rotateSnap) * rotateSnap
+}
+
+// normalizeAngle keeps deg in (-180, 180]
+func normalizeAngle(deg float32) float32 {
+	deg = float32(math.Mod(float64(deg), 360))
+	if deg > 180 {
+		deg -= 360
+	} else if deg <= -180 {
+		deg += 360
+	}
+	return deg
+}
+
+func offsetRect(r rtree.Rect, dx, dy float32) rtree.Rect {
+	return rtree.Rect{
+		MinX: r.MinX + float64(dx), MinY: r.MinY + float64(dy),
+		MaxX: r.MaxX + float64(dx), MaxY: r.MaxY + float64(dy),
+	}
+}
+
+// place gives the node of s a w x h box centered on world point
+// (cx, cy), rotated around that center. Nodes of auto layout parents
+// keep the position the layout gives them.
+func (e *Engine) place(s dragStart, cx, cy, w, h, rotation float32) {
+	node := s.node
+
+	x, y := float32(0), float32(0)
+	if inverse, ok := s.parent.Invert(); ok {
+		x, y = inverse.Apply(cx, cy)
+	}
+	x, y = x-w/2, y-h/2
+	// Local matrix subtracts the scroll of the parent
+	if p := node.Parent; p != nil && p.IsScrollContainer() {
+		x, y = x+p.ScrollX, y+p.ScrollY
+	}
+
+	e.resizeNode(node, e.editNode, func() {
+		st := node.Style
+		if s.freeLayout {
+			st.Left, st.Top = layout.Px(x), layout.Px(y)
+		}
+		st.Width, st.Height = layout.Px(w), layout.Px(h)
+		st.Rotation = rotation
+	})
+}
+
+// drawSelection draws the box around the selection and its resize
+// handles, the strokes stay one screen pixel wide at any zoom
+func (e *Engine) drawSelection() {
+	w, h, frame, ok := e.selectionFrame()
+	if !ok {
+		return
+	}
+
+	scale := e.viewScale()
+	cb := e.commandBuffer
+	cb.WriteSetMatrix(e.viewport.Matrix().Multiply(frame))
+	cb.WriteSetStroke(marqueeStroke, 1/scale)
+	cb.WriteDrawRect(0, 0, w, h, protocol.DrawStroke)
+
+	cb.WriteSetFill(handleFill)
+	size := handleSize / scale
+	for _, handle := range resizeHandles {
+		x, y := handlePosition(handle, w, h)
+		cb.WriteDrawRect(x-size/2, y-size/2, size, size, protocol.DrawFill|protocol.DrawStroke)
+	}
+}
@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"engo/internal/protocol"
+	"engo/pkg/layout"
+)
+
+const transformDocument = `{"version":1,"root":{"id":1,"type":"PAGE","children":[
+	{"id":2,"type":"FRAME","style":{"left":100,"top":100,"width":100,"height":50}},
+	{"id":3,"type":"FRAME","style":{"left":300,"top":100,"width":100,"height":100}}
+]}}`
+
+// release lifts the button over one Update, modifiers still held
+func release(x, y float32, modifiers uint32) {
+	pointerTo(x, y, 0, modifiers)
+	Update(0)
+}
+
+func expectBox(t *testing.T, id uint32, left, top, width, height float32) {
+	t.Helper()
+	st := engine.findNode(id).Style
+	got := [4]float32{st.Left.Value, st.Top.Value, st.Width.Value, st.Height.Value}
+	want := [4]float32{left, top, width, height}
+	for i := range got {
+		if math.Abs(float64(got[i]-want[i])) > 1e-3 {
+			t.Errorf("Expected box %v, got %v", want, got)
+			return
+		}
+	}
+}
+
+func TestTransform_Move(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(transformDocument))
+	Update(0)
+
+	drag(150, 125, 170, 135, 0)
+	release(170, 135, 0)
+	expectBox(t, 2, 120, 110, 100, 50)
+	if ids := selectedIDs(); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("Pressing a node should select it, got %v", ids)
+	}
+
+	// The whole drag is one undo step
+	if !Undo() {
+		t.Fatal("Drag should be undoable")
+	}
+	expectBox(t, 2, 100, 100, 100, 50)
+
+	// Shift keeps the dominant axis
+	drag(150, 125, 180, 130, protocol.ModShift)
+	release(180, 130, protocol.ModShift)
+	expectBox(t, 2, 130, 100, 100, 50)
+
+	// Shift click without a drag toggles the node
+	drag(150, 125, 150, 125, protocol.ModShift)
+	release(150, 125, protocol.ModShift)
+	if ids := selectedIDs(); len(ids) != 0 {
+		t.Errorf("Shift click should deselect, got %v", ids)
+	}
+}
+
+func TestTransform_Resize(t *testing.T) {
+	cases := []struct {
+		name                     string
+		x0, y0, x1, y1           float32
+		modifiers                uint32
+		left, top, width, height float32
+	}{
+		{"corner", 200, 150, 220, 170, 0, 100, 100, 120, 70},
+		{"edge", 150, 100, 150, 80, 0, 100, 80, 100, 70},
+		{"aspect lock", 200, 150, 300, 160, protocol.ModShift, 100, 100, 200, 100},
+		{"from center", 200, 125, 210, 125, protocol.ModAlt, 90, 100, 120, 50},
+		{"flip", 100, 125, 250, 125, 0, 200, 100, 50, 50},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			Init()
+			loadDocument(t, []byte(transformDocument))
+			engine.SetSelection(engine.findNode(2), true)
+			Update(0)
+
+			drag(c.x0, c.y0, c.x1, c.y1, c.modifiers)
+			release(c.x1, c.y1, c.modifiers)
+			expectBox(t, 2, c.left, c.top, c.width, c.height)
+		})
+	}
+}
+
+func TestTransform_Rotate(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(transformDocument))
+	engine.SetSelection(engine.findNode(2), true)
+	Update(0)
+
+	// Just outside the bottom right corner, around center (150, 125)
+	drag(212, 162, 140, 190, 0)
+	release(140, 190, 0)
+
+	st := engine.findNode(2).Style
+	if r := st.Rotation; r == 0 || math.Mod(float64(r), rotateSnap) != 0 {
+		t.Errorf("Rotation should snap to %v degrees, got %v", rotateSnap, r)
+	}
+	expectBox(t, 2, 100, 100, 100, 50)
+
+	// Handles follow the rotated node
+	rotated := worldMatrix(engine.findNode(2))
+	x, y := rotated.Apply(100, 50)
+	if handle := engine.handleAt(x, y); handle != handleRight|handleBottom {
+		t.Errorf("Expected the bottom right handle, got %v", handle)
+	}
+
+	// Ctrl turns snapping off
+	snapped := st.Rotation
+	Undo()
+	Update(0)
+	drag(212, 162, 140, 190, protocol.ModCtrl)
+	release(140, 190, protocol.ModCtrl)
+	if r := st.Rotation; r == snapped || math.Mod(float64(r), rotateSnap) == 0 {
+		t.Errorf("Rotation with Ctrl should follow the pointer, got %v", r)
+	}
+}
+
+func TestTransform_Selection(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(transformDocument))
+	engine.setSelection(nil)
+	SelectAll()
+	Update(0)
+
+	// Box and its 8 handles are drawn, the scene is only filled
+	rects := 0
+	for _, payload := range commands(protocol.OpDrawRect) {
+		if protocol.DrawMode(payload[4])&protocol.DrawStroke != 0 {
+			rects++
+		}
+	}
+	if rects != 9 {
+		t.Errorf("Expected 9 stroked rects, got %d", rects)
+	}
+
+	// Several nodes scale around the box they share
+	drag(400, 200, 700, 200, 0)
+	release(700, 200, 0)
+	expectBox(t, 2, 100, 100, 200, 50)
+	expectBox(t, 3, 500, 100, 200, 100)
+	if st := engine.findNode(3).Style; st.Left.Unit != layout.UnitPixel {
+		t.Errorf("Position should stay in pixel")
+	}
+}
@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"math"
+
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+)
+
+// Viewport is the camera of the canvas: a point of the world is drawn
+// at world * Scale + Translate on the screen
+type Viewport struct {
+	Scale      float32
+	TranslateX float32
+	TranslateY float32
+	// Size of the canvas in CSS pixel, set by JS
+	Width, Height float32
+}
+
+const (
+	DefaultScale      = 1.0
+	DefaultTranslateX = 0.0
+	DefaultTranslateY = 0.0
+)
+
+// Zoom range, 2% to 25600%
+const (
+	MinScale = 0.02
+	MaxScale = 256
+)
+
+// Wheel pixels to double the zoom
+const zoomWheelStep = 100
+
+// Space around what zoom to fit shows (screen pixel)
+const zoomFitPadding = 40
+
+func NewViewport() *Viewport {
+	return &Viewport{
+		Scale:      DefaultScale,
+		TranslateX: DefaultTranslateX,
+		TranslateY: DefaultTranslateY,
+	}
+}
+
+func NewViewPortWithArgs(scale, translateX, translateY float32) *Viewport {
+	return &Viewport{
+		Scale:      scale,
+		TranslateX: translateX,
+		TranslateY: translateY,
+	}
+}
+
+// Matrix maps world to screen space
+func (v *Viewport) Matrix() protocol.Matrix {
+	return protocol.Matrix{v.Scale, 0, 0, v.Scale, v.TranslateX, v.TranslateY}
+}
+
+func (v *Viewport) ScreenToWorld(x, y float32) (float32, float32) {
+	return (x - v.TranslateX) / v.Scale, (y - v.TranslateY) / v.Scale
+}
+
+func (v *Viewport) WorldToScreen(x, y float32) (float32, float32) {
+	return x*v.Scale + v.TranslateX, y*v.Scale + v.TranslateY
+}
+
+// Pan moves the world by (dx, dy) screen pixels
+func (v *Viewport) Pan(dx, dy float32) {
+	if !finite(dx, dy) {
+		return
+	}
+	v.TranslateX += dx
+	v.TranslateY += dy
+}
+
+// ZoomAt sets the zoom, clamped to the range, keeping the world point
+// under screen point (x, y) in place. Min and max let NaN through, so
+// a non-finite or non-positive scale from JS is ignored instead.
+func (v *Viewport) ZoomAt(scale, x, y float32) {
+	if !(scale > 0) || !finite(scale, x, y) {
+		return
+	}
+	scale = min(max(scale, MinScale), MaxScale)
+	wx, wy := v.ScreenToWorld(x, y)
+	v.Scale = scale
+	v.TranslateX, v.TranslateY = x-wx*scale, y-wy*scale
+}
+
+// ZoomBy multiplies the zoom by factor around screen point (x, y)
+func (v *Viewport) ZoomBy(factor, x, y float32) {
+	if !(factor > 0) || !finite(factor) {
+		return
+	}
+	// A huge factor overflows to +Inf, that is still the max zoom
+	v.ZoomAt(min(v.Scale*factor, MaxScale), x, y)
+}
+
+// finite reports whether none of vs is NaN or ±Inf
+func finite(vs ...float32) bool {
+	for _, v := range vs {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fit zooms so the world rect r fills the canvas, centered, with
+// padding screen pixels around it
+func (v *Viewport) Fit(r rtree.Rect, padding float32) {
+	w, h := float32(r.MaxX-r.MinX), float32(r.MaxY-r.MinY)
+	aw, ah := v.Width-2*padding, v.Height-2*padding
+	if aw <= 0 || ah <= 0 {
+		return
+	}
+
+	scale := float32(MaxScale)
+	if w > 0 {
+		scale = min(scale, aw/w)
+	}
+	if h > 0 {
+		scale = min(scale, ah/h)
+	}
+	v.Scale = max(scale, MinScale)
+
+	cx, cy := float32(r.MinX+r.MaxX)/2, float32(r.MinY+r.MaxY)/2
+	v.TranslateX, v.TranslateY = v.Width/2-cx*v.Scale, v.Height/2-cy*v.Scale
+}
+
+// wheel zooms around the pointer with Ctrl or Cmd, trackpad pinches
+// come as wheel with Ctrl too, and pans otherwise
+func (e *Engine) wheel(dx, dy float32, zoom bool) {
+	if dx == 0 && dy == 0 {
+		return
+	}
+
+	if zoom {
+		factor := float32(math.Exp2(float64(-dy / zoomWheelStep)))
+		e.viewport.ZoomBy(factor, e.input.X, e.input.Y)
+		return
+	}
+	e.viewport.Pan(-dx, -dy)
+}
+
+// panDrag moves the canvas with the pointer while space or the middle
+// button is held
+type panDrag struct {
+	active bool
+	// Space is held down
+	space bool
+	x, y  float32
+}
+
+func (e *Engine) startPan() {
+	e.pan.active = true
+	e.pan.x, e.pan.y = e.inputState.Pointer()
+}
+
+// updateViewport applies wheel, keys and the pan drag of this frame
+func (e *Engine) updateViewport() {
+	in := &e.input
+	e.wheel(in.WheelX, in.WheelY, in.Modifiers&(protocol.ModCtrl|protocol.ModMeta) != 0)
+
+	for _, key := range in.Keys {
+		e.keyAction(key.Code, key.Action)
+	}
+
+	p := &e.pan
+	if !p.active {
+		return
+	}
+
+	e.viewport.Pan(in.X-p.x, in.Y-p.y)
+	p.x, p.y = in.X, in.Y
+	if in.Buttons&(protocol.ButtonLeft|protocol.ButtonMiddle) == 0 {
+		p.active = false
+	}
+}
+
+// keyAction follows the keys the viewport cares about
+func (e *Engine) keyAction(code, action uint32) {
+	if code == protocol.KeySpace {
+		e.pan.space = action == protocol.KeyDown
+	}
+}
+
+// zoomToFit shows every node of the page
+func (e *Engine) zoomToFit() bool {
+	children := e.rootNode.Children
+	if len(children) == 0 {
+		return false
+	}
+
+	bounds := children[0].LastWorldMBR
+	for _, child := range children[1:] {
+		bounds = rtree.Union(bounds, child.LastWorldMBR)
+	}
+	e.viewport.Fit(bounds, zoomFitPadding)
+	return true
+}
+
+func (e *Engine) zoomToSelection() bool {
+	if len(e.selection) == 0 {
+		return false
+	}
+
+	e.viewport.Fit(e.selectionBounds(), zoomFitPadding)
+	return true
+}
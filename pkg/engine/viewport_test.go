@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+)
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
+
+func TestViewport_ZoomAt(t *testing.T) {
+	v := NewViewport()
+	v.Pan(10, 20)
+	wx, wy := v.ScreenToWorld(300, 200)
+
+	v.ZoomAt(4, 300, 200)
+	if x, y := v.WorldToScreen(wx, wy); !near(x, 300) || !near(y, 200) {
+		t.Errorf("Point under the cursor moved to (%v, %v)", x, y)
+	}
+
+	v.ZoomBy(1000, 0, 0)
+	if v.Scale != MaxScale {
+		t.Errorf("Expected zoom clamped to %v, got %v", MaxScale, v.Scale)
+	}
+	v.ZoomAt(0.001, 0, 0)
+	if v.Scale != MinScale {
+		t.Errorf("Expected zoom clamped to %v, got %v", MinScale, v.Scale)
+	}
+
+	// Broken values from JS leave the camera as it was
+	before := *v
+	nan, inf := float32(math.NaN()), float32(math.Inf(1))
+	v.ZoomAt(nan, 0, 0)
+	v.ZoomAt(inf, 0, 0)
+	v.ZoomAt(0, 0, 0)
+	v.ZoomAt(-2, 0, 0)
+	v.ZoomAt(2, nan, 0)
+	v.ZoomBy(nan, 0, 0)
+	v.ZoomBy(-inf, 0, 0)
+	v.ZoomBy(0, 0, 0)
+	v.Pan(inf, 0)
+	if *v != before {
+		t.Errorf("Non-finite zoom should be ignored, got %+v", *v)
+	}
+	v.ZoomBy(float32(math.MaxFloat32), 0, 0)
+	if v.Scale != MaxScale {
+		t.Errorf("Overflowing factor should zoom to %v, got %v", MaxScale, v.Scale)
+	}
+}
+
+func TestViewport_Fit(t *testing.T) {
+	v := NewViewport()
+	v.Width, v.Height = 880, 480
+	v.Fit(rtree.Rect{MinX: 100, MinY: 100, MaxX: 500, MaxY: 300}, 40)
+
+	if v.Scale != 2 {
+		t.Errorf("Expected zoom 2, got %v", v.Scale)
+	}
+	if x, y := v.WorldToScreen(300, 200); !near(x, 440) || !near(y, 240) {
+		t.Errorf("Rect should be centered, got (%v, %v)", x, y)
+	}
+}
+
+// writeWheel adds a wheel delta the way the JS glue does
+func writeWheel(dx, dy float32) {
+	input := unsafe.Slice((*uint32)(engine.inputState.GetPtr()), protocol.InputWords)
+	input[protocol.InputWheelX] = math.Float32bits(dx)
+	input[protocol.InputWheelY] = math.Float32bits(dy)
+}
+
+func TestViewport_Wheel(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(transformDocument))
+
+	pointerTo(100, 100, 0, 0)
+	writeWheel(30, 40)
+	Update(0)
+	if v := engine.viewport; v.TranslateX != -30 || v.TranslateY != -40 {
+		t.Errorf("Wheel should pan, got (%v, %v)", v.TranslateX, v.TranslateY)
+	}
+
+	// Ctrl zooms in around the pointer
+	wx, wy := engine.screenToWorld(100, 100)
+	pointerTo(100, 100, 0, protocol.ModCtrl)
+	writeWheel(0, -zoomWheelStep)
+	Update(0)
+	v := engine.viewport
+	if v.Scale != 2 {
+		t.Errorf("Expected zoom 2, got %v", v.Scale)
+	}
+	if x, y := v.WorldToScreen(wx, wy); !near(x, 100) || !near(y, 100) {
+		t.Errorf("Point under the cursor moved to (%v, %v)", x, y)
+	}
+
+	// Root transform of the frame is the camera
+	root := commands(protocol.OpSetMatrix)[0]
+	if math.Float32frombits(root[0]) != 2 || math.Float32frombits(root[4]) != v.TranslateX {
+		t.Errorf("Frame should start with the camera, got %v", root)
+	}
+
+	// Hit testing follows the camera, frame 2 is at (100, 100) in world
+	x, y := v.WorldToScreen(150, 125)
+	if id := HitTest(x, y); id != 2 {
+		t.Errorf("Expected 2 under the zoomed pointer, got %d", id)
+	}
+}
+
+func TestViewport_Pan(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		button int32
+		held   uint32
+	}{
+		{"space", 0, protocol.ButtonLeft},
+		{"middle button", 1, protocol.ButtonMiddle},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			Init()
+			loadDocument(t, []byte(transformDocument))
+			Update(0)
+
+			if c.button == 0 {
+				OnKeyAction(int32(protocol.KeySpace), int32(protocol.KeyDown), 0)
+			}
+			pointerTo(150, 125, c.held, 0)
+			Update(0)
+			OnMouseAction(c.button, 0, 0)
+			pointerTo(180, 100, c.held, 0)
+			Update(0)
+
+			if v := engine.viewport; v.TranslateX != 30 || v.TranslateY != -25 {
+				t.Errorf("Expected pan (30, -25), got (%v, %v)", v.TranslateX, v.TranslateY)
+			}
+			if len(engine.selection) != 0 || engine.transform.kind != dragNone {
+				t.Errorf("Panning should not select or move nodes")
+			}
+
+			pointerTo(200, 100, 0, 0)
+			Update(0)
+			if engine.pan.active || engine.viewport.TranslateX != 50 {
+				t.Errorf("Release should end the pan")
+			}
+		})
+	}
+}
+
+func TestZoomToFit(t *testing.T) {
+	Init()
+	loadDocument(t, []byte(transformDocument))
+	Resize(640, 480, 1)
+	Update(0)
+
+	// Frames cover (100, 100) to (400, 200)
+	if !ZoomToFit() {
+		t.Fatal("Page has nodes to fit")
+	}
+	v := engine.viewport
+	if x, _ := v.WorldToScreen(100, 0); !near(x, zoomFitPadding) {
+		t.Errorf("Expected left edge at the padding, got %v", x)
+	}
+
+	engine.SetSelection(engine.findNode(2), true)
+	if !ZoomToSelection() {
+		t.Fatal("Selection has a node to fit")
+	}
+	if x, y := v.WorldToScreen(150, 125); !near(x, 320) || !near(y, 240) {
+		t.Errorf("Selection should be centered, got (%v, %v)", x, y)
+	}
+
+	ClearSelection()
+	if ZoomToSelection() {
+		t.Errorf("Nothing to zoom to")
+	}
+}
@@ -2,7 +2,9 @@ package fiber
 
 import (
 	"engo/internal/protocol"
+	"engo/pkg/layout"
 	"engo/pkg/scene"
+	"engo/pkg/style"
 )
 
 type EffectTag uint32
@@ -13,13 +15,14 @@ const (
 	EffectUpdate    EffectTag = 1 << 1 // Props thay đổi, cần vẽ lại
 	EffectDeletion  EffectTag = 1 << 2 // Node bị xóa
 	EffectLayout    EffectTag = 1 << 3 // Cần tính lại Flexbox
+	EffectMove      EffectTag = 1 << 4 // Node đổi vị trí trong danh sách con
 )
 
 type Fiber struct {
 	Node *scene.Node
 
 	// For compare if the fiber node order is change
-	Index uint8
+	Index int
 
 	Props any
 
@@ -36,6 +39,8 @@ type Fiber struct {
 	Tag protocol.OpCode
 	Key uint32
 
+	GlobalMatrix protocol.Matrix
+
 	LayoutX, LayoutY, LayoutW, LayoutH float32
 }
 
@@ -77,8 +82,7 @@ func (f *Fiber) IsSameNode(node *scene.Node) bool {
 
 	// 2. So sánh Loại (Type/Tag)
 	// Ví dụ: Không thể tái sử dụng Fiber của "Rect" cho "Text"
-	// Giả sử bạn có hàm helper chuyển String Type sang OpCode Tag
-	nodeTag := protocol.GetTagFromStr(node.Type)
+	nodeTag := GetTagFromType(node.Type)
 	if f.Tag != nodeTag {
 		return false
 	}
@@ -86,6 +90,25 @@ func (f *Fiber) IsSameNode(node *scene.Node) bool {
 	return true
 }
 
+// GetTagFromType maps a scene node type to the primitive
+// used to draw it, container-like nodes are drawn as groups
+func GetTagFromType(nodeType scene.NodeType) protocol.OpCode {
+	switch nodeType {
+	case scene.Frame, scene.Polygon, scene.Component, scene.Instance, scene.Sticky:
+		return protocol.OpDrawRect
+	case scene.Line, scene.Connector:
+		return protocol.OpDrawLine
+	case scene.Vector:
+		return protocol.OpPathBegin
+	case scene.Text:
+		return protocol.OpDrawText
+	case scene.Image:
+		return protocol.OpDrawImg
+	}
+
+	return protocol.OpPushGroup
+}
+
 func (f *Fiber) HasPropsChanged() bool {
 	return f.Node.Flags != scene.FlagNone
 }
@@ -97,16 +120,41 @@ func (f *Fiber) ComputeMatrix(parentMatrix protocol.Matrix) {
 
 	// 2. Nhân với ma trận của cha
 	// World = Parent * Local
+	// Scroll offset của cha (nếu là scroll container) đã nằm trong Local
 	f.GlobalMatrix = parentMatrix.Multiply(localMat)
-
-	// 3. Nếu node này là ScrollView, có thể cần nhân thêm ScrollOffset
-	// ...
 }
 
-// ComputeLayout (Nếu dùng Flexbox)
-func (f *Fiber) ComputeLayout() {
-	// Nếu node này là Flex container, chạy thuật toán layout đơn giản
-	// hoặc gọi tới Yoga engine để tính toán x,y,w,h cho con cái
+// ComputeLayout runs the layout pass on the subtree of this fiber and
+// stores boxes into ComputedStyle of every scene node below it. The
+// fiber's own box is kept, children fibers pick their box up in
+// beginWork through applyLayout. host measures leaves, it may be nil.
+func (f *Fiber) ComputeLayout(host Host) {
+	var nodes []*scene.Node
+	var boxes []*layout.Node
+	var parentW float32
+	if f.Node.Parent != nil {
+		_, _, parentW, _ = f.Node.Parent.Box()
+	}
+	root := newLayoutNode(f.Node, parentW, host, &nodes, &boxes)
+
+	x, y, w, h := f.Node.Box()
+	root.X, root.Y = x, y
+	layout.Compute(root, w, h)
+
+	for i, node := range nodes {
+		computed := node.ComputedStyle
+		if computed == nil {
+			computed = style.NewStyle()
+			node.ComputedStyle = computed
+		}
+		if node.Style != nil {
+			*computed = *node.Style
+		}
+
+		box := boxes[i]
+		computed.Left, computed.Top = layout.Px(box.X), layout.Px(box.Y)
+		computed.Width, computed.Height = layout.Px(box.W), layout.Px(box.H)
+	}
 }
 
 func (f *Fiber) MarkUpdate() {
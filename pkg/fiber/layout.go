@@ -0,0 +1,59 @@
+package fiber
+
+import (
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+// newLayoutNode mirrors node and its descendants into a layout tree,
+// boxes[i] is the layout node of scene node nodes[i]. Percent margins,
+// paddings and borders resolve against parentW, the last known width
+// of the parent. Leaves get their Measure from host.
+func newLayoutNode(node *scene.Node, parentW float32, host Host, nodes *[]*scene.Node, boxes *[]*layout.Node) *layout.Node {
+	box := &layout.Node{}
+	if host != nil {
+		box.Measure = host.Measure(node)
+	}
+
+	if s := node.Style; s != nil {
+		box.Mode = s.Layout
+		box.Flex = s.Flex
+		box.Grid = s.Grid
+		box.GridItem = s.GridItem
+		box.Width, box.Height = s.Width, s.Height
+		box.MinWidth, box.MaxWidth = s.MinWidth, s.MaxWidth
+		box.MinHeight, box.MaxHeight = s.MinHeight, s.MaxHeight
+		box.AspectRatio = s.AspectRatio
+		box.Position = layout.Position(s.Position)
+		box.Left, box.Top, box.Right, box.Bottom = s.Left, s.Top, s.Right, s.Bottom
+		box.Scroll = s.Overflow == style.OverflowScroll
+		box.Margin = s.Margin.Resolve(parentW)
+		box.Padding = s.Padding.Resolve(parentW)
+		box.Border = s.BorderWidth.Resolve(parentW)
+	}
+
+	box.ScrollX, box.ScrollY = node.ScrollX, node.ScrollY
+
+	*nodes = append(*nodes, node)
+	*boxes = append(*boxes, box)
+
+	_, _, w, _ := node.Box()
+	for _, child := range node.Children {
+		box.AppendChild(newLayoutNode(child, w, host, nodes, boxes))
+	}
+
+	return box
+}
+
+// applyLayout copies the computed box of the scene node into the fiber,
+// flags the fiber with EffectLayout if its box moved in world space
+func (f *Fiber) applyLayout() {
+	f.LayoutX, f.LayoutY, f.LayoutW, f.LayoutH = f.Node.Box()
+
+	old := f.Alternate
+	if old == nil || old.GlobalMatrix != f.GlobalMatrix ||
+		old.LayoutW != f.LayoutW || old.LayoutH != f.LayoutH {
+		f.Flags |= EffectLayout
+	}
+}
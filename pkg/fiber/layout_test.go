@@ -0,0 +1,146 @@
+package fiber
+
+import (
+	"testing"
+
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+type fakeHost struct {
+	updated map[uint32]int
+}
+
+func (h *fakeHost) RemoveSpatial(node *scene.Node) {}
+func (h *fakeHost) RemoveDOMOverlay(id uint32)     {}
+func (h *fakeHost) UpdateSpatial(node *scene.Node) { h.updated[node.ID]++ }
+func (h *fakeHost) Measure(node *scene.Node) layout.MeasureFunc {
+	return nil
+}
+
+func newTestNode(id uint32, nodeType scene.NodeType, s style.Style) *scene.Node {
+	node := scene.NewNode(nodeType, nil)
+	node.ID = id
+	node.Style = &s
+	return node
+}
+
+func TestReconciler_ComputeLayout(t *testing.T) {
+	page := newTestNode(1, scene.Page, style.Style{})
+	frame := newTestNode(2, scene.Frame, style.Style{
+		Left: layout.Px(100), Top: layout.Px(50), Width: layout.Px(300), Height: layout.Px(100), Padding: style.UniformEdges(layout.Px(10)),
+		Layout: layout.ModeFlex,
+		Flex:   layout.Flex{JustifyContent: layout.JustifyCenter, AlignItem: layout.AlignCenter},
+	})
+	a := newTestNode(3, scene.Polygon, style.Style{Width: layout.Px(40), Height: layout.Px(40)})
+	b := newTestNode(4, scene.Polygon, style.Style{Width: layout.Px(60), Height: layout.Px(20), Left: layout.Px(5)})
+
+	page.AppendChild(frame)
+	frame.AppendChild(a)
+	frame.AppendChild(b)
+	page.MarkDirty(scene.FlagLayoutDirty)
+
+	host := &fakeHost{updated: map[uint32]int{}}
+	r := NewReconciler(page, host)
+	r.ScheduleUpdate(page)
+	if r.WorkLoop(0) {
+		t.Fatalf("WorkLoop should finish without budget")
+	}
+
+	frameFiber := r.CurrentRoot.Child
+	aFiber := frameFiber.Child
+	bFiber := aFiber.Sibling
+
+	// Content 280x80, items 100 wide centered: starts at 10 + 90
+	if aFiber.LayoutX != 100 || aFiber.LayoutY != 30 || aFiber.LayoutW != 40 {
+		t.Errorf("Wrong box for a: %v %v %v", aFiber.LayoutX, aFiber.LayoutY, aFiber.LayoutW)
+	}
+	// Left of a relative node shifts it from its flex position
+	if bFiber.LayoutX != 145 || bFiber.LayoutY != 40 {
+		t.Errorf("Wrong box for b: %v %v", bFiber.LayoutX, bFiber.LayoutY)
+	}
+
+	// World position goes through the frame
+	if bFiber.GlobalMatrix[4] != 245 || bFiber.GlobalMatrix[5] != 90 {
+		t.Errorf("Wrong world matrix for b: %v", bFiber.GlobalMatrix)
+	}
+
+	if b.ComputedStyle == nil || b.ComputedStyle.Left != layout.Px(145) {
+		t.Errorf("Layout should be stored in ComputedStyle")
+	}
+	if b.Style.Left != layout.Px(5) {
+		t.Errorf("Layout must not overwrite Style")
+	}
+
+	for _, id := range []uint32{2, 3, 4} {
+		if host.updated[id] != 1 {
+			t.Errorf("Node %d should have its spatial bounds refreshed once, got %d", id, host.updated[id])
+		}
+	}
+}
+
+func TestReconciler_ComputeGridLayout(t *testing.T) {
+	page := newTestNode(1, scene.Page, style.Style{})
+	frame := newTestNode(2, scene.Frame, style.Style{
+		Width: layout.Px(200), Height: layout.Px(100),
+		Layout: layout.ModeGrid,
+		Grid:   layout.Grid{Columns: []layout.Track{layout.Fr(1), layout.Fr(3)}, ColumnGap: 20},
+	})
+	a := newTestNode(3, scene.Polygon, style.Style{Width: layout.Px(30), Height: layout.Px(30)})
+	b := newTestNode(4, scene.Polygon, style.Style{Width: layout.Px(30), Height: layout.Px(30), GridItem: layout.GridItem{Column: 2}})
+
+	page.AppendChild(frame)
+	frame.AppendChild(a)
+	frame.AppendChild(b)
+	page.MarkDirty(scene.FlagLayoutDirty)
+
+	r := NewReconciler(page, &fakeHost{updated: map[uint32]int{}})
+	r.ScheduleUpdate(page)
+	r.WorkLoop(0)
+
+	// 180 shared 1:3 after the gap, b starts at 45 + 20
+	bFiber := r.CurrentRoot.Child.Child.Sibling
+	if bFiber.LayoutX != 65 || bFiber.LayoutY != 0 || bFiber.LayoutW != 30 {
+		t.Errorf("Wrong grid box for b: %v %v %v", bFiber.LayoutX, bFiber.LayoutY, bFiber.LayoutW)
+	}
+}
+
+func TestReconciler_Scroll(t *testing.T) {
+	page := newTestNode(1, scene.Page, style.Style{})
+	frameStyle := style.NewStyle()
+	frameStyle.Width, frameStyle.Height = layout.Px(100), layout.Px(100)
+	frameStyle.Overflow = style.OverflowScroll
+	frame := newTestNode(2, scene.Frame, *frameStyle)
+
+	itemStyle := style.NewStyle()
+	itemStyle.Width, itemStyle.Height = layout.Px(100), layout.Px(20)
+	itemStyle.Top = layout.Px(50)
+	item := newTestNode(3, scene.Polygon, *itemStyle)
+
+	itemStyle.Top = layout.Px(0)
+	itemStyle.Position = style.PositionSticky
+	header := newTestNode(4, scene.Polygon, *itemStyle)
+
+	page.AppendChild(frame)
+	frame.AppendChild(item)
+	frame.AppendChild(header)
+	frame.ScrollY = 30
+	page.MarkDirty(scene.FlagLayoutDirty)
+
+	r := NewReconciler(page, &fakeHost{updated: map[uint32]int{}})
+	r.ScheduleUpdate(page)
+	r.WorkLoop(0)
+
+	itemFiber := r.CurrentRoot.Child.Child
+	headerFiber := itemFiber.Sibling
+
+	// Children move up by the scroll offset
+	if itemFiber.LayoutY != 50 || itemFiber.GlobalMatrix[5] != 20 {
+		t.Errorf("Wrong scrolled item: layout %v, world %v", itemFiber.LayoutY, itemFiber.GlobalMatrix[5])
+	}
+	// The sticky header stays at the top of the frame
+	if headerFiber.LayoutY != 30 || headerFiber.GlobalMatrix[5] != 0 {
+		t.Errorf("Wrong sticky header: layout %v, world %v", headerFiber.LayoutY, headerFiber.GlobalMatrix[5])
+	}
+}
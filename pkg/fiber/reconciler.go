@@ -2,26 +2,42 @@ package fiber
 
 import (
 	"engo/internal/protocol"
-	"engo/pkg/engine"
+	"engo/pkg/layout"
 	"engo/pkg/scene"
 	"time"
 )
 
+// Host is implemented by the engine, it lets the reconciler clean up
+// engine-owned structures without importing the engine package
+type Host interface {
+	// Remove node from spatial index
+	RemoveSpatial(node *scene.Node)
+	// Refresh world bounds of node after layout moved it
+	UpdateSpatial(node *scene.Node)
+	RemoveDOMOverlay(id uint32)
+	// Intrinsic size of leaves like text, nil if node has none
+	Measure(node *scene.Node) layout.MeasureFunc
+}
+
 type Reconciler struct {
 	CurrentRoot    *Fiber // Cây đang hiển thị
 	WipRoot        *Fiber // Cây đang xây dựng (Work In Progress)
 	NextUnitOfWork *Fiber // Con trỏ "Cursor" hiện tại
 
-	engine interface{}
+	host Host
 }
 
-func NewReconciler(engine interface{}) *Reconciler {
+func NewReconciler(rootNode *scene.Node, host Host) *Reconciler {
 	return &Reconciler{
-		CurrentRoot:    nil,
+		CurrentRoot: &Fiber{
+			Node: rootNode,
+			Tag:  GetTagFromType(rootNode.Type),
+			Key:  rootNode.ID,
+		},
 		WipRoot:        nil,
 		NextUnitOfWork: nil,
 
-		engine: engine,
+		host: host,
 	}
 }
 
@@ -89,11 +105,20 @@ func (r *Reconciler) beginWork(fiber *Fiber) *Fiber {
 		parentMatrix = fiber.Parent.GlobalMatrix
 	}
 
+	// Layout runs once per update, from the root of the work
+	if fiber == r.WipRoot && node.Flags&(scene.FlagLayoutDirty|scene.FlagSubtreeDirty) != 0 {
+		fiber.ComputeLayout(r.host)
+	}
+
 	fiber.ComputeMatrix(parentMatrix)
+	fiber.applyLayout()
 
+	// Clean and placed where it was, the committed subtree still holds.
+	// A moved parent goes on so the matrices of its children follow.
 	if fiber.Alternate != nil &&
 		(node.Flags&scene.FlagLayoutDirty == 0) &&
-		(node.Flags&scene.FlagSubtreeDirty == 0) {
+		(node.Flags&scene.FlagSubtreeDirty == 0) &&
+		fiber.Flags&EffectLayout == 0 {
 		return r.bailoutOnAlreadyFinishedWork(fiber, fiber.Alternate)
 	}
 
@@ -107,15 +132,15 @@ func (r *Reconciler) beginWork(fiber *Fiber) *Fiber {
 	return fiber.Child
 }
 
+// bailoutOnAlreadyFinishedWork reuses the committed children of
+// alternate and skips the subtree, nothing below it needs work
 func (r *Reconciler) bailoutOnAlreadyFinishedWork(current *Fiber, alternate *Fiber) *Fiber {
-	child := alternate.Child
-
-	if child != nil {
-		current.Child = child
+	current.Child = alternate.Child
+	for child := current.Child; child != nil; child = child.Sibling {
 		child.Parent = current
 	}
 
-	return child
+	return nil
 }
 
 func (r *Reconciler) reconcileChildren(returnFiber *Fiber, newChildren []*scene.Node) {
@@ -162,7 +187,7 @@ func (r *Reconciler) reconcileChildren(returnFiber *Fiber, newChildren []*scene.
 			// create new fiber if new child doesn't exist in old fiber
 			newFiber = &Fiber{
 				Node:  newNode,
-				Tag:   protocol.GetTagFromStr(newNode.Type),
+				Tag:   GetTagFromType(newNode.Type),
 				Key:   newNode.ID,
 				Flags: EffectPlacement, // Đánh dấu là Mới
 			}
@@ -198,7 +223,8 @@ func (r *Reconciler) CommitRoot() {
 		return
 	}
 
-	if finishedWork.SubtreeFlags != EffectNone || finishedWork.Flags != EffectNone {
+	if finishedWork.SubtreeFlags != EffectNone || finishedWork.Flags != EffectNone ||
+		finishedWork.Node.Flags != scene.FlagNone {
 		r.commitWork(finishedWork)
 	}
 
@@ -233,11 +259,25 @@ func (r *Reconciler) commitWork(fiber *Fiber) {
 	if fiber.Flags&EffectUpdate != 0 {
 		// TODO: Complete this
 	}
+	if fiber.Flags&EffectLayout != 0 && r.host != nil {
+		r.host.UpdateSpatial(fiber.Node)
+	}
+
+	// Committed, the next update bails out here unless it is edited
+	// again. Dirty nodes may have no effects, follow the scene flags too.
+	descend := fiber.SubtreeFlags != EffectNone
+	if node := fiber.Node; node != nil {
+		descend = descend || node.Flags&scene.FlagSubtreeDirty != 0
+		node.Flags &^= scene.FlagLayoutDirty | scene.FlagSubtreeDirty |
+			scene.FlagContentDirty | scene.FlagTransformDirty
+	}
 
-	if fiber.SubtreeFlags != EffectNone {
+	if descend {
 		r.commitWork(fiber.Child)
-		r.commitWork(fiber.Sibling)
 	}
+	// Siblings carry their own flags, don't skip them
+	// because this fiber's subtree is clean
+	r.commitWork(fiber.Sibling)
 }
 
 func (r *Reconciler) commitDeletion(fiberToDelete *Fiber) {
@@ -245,12 +285,11 @@ func (r *Reconciler) commitDeletion(fiberToDelete *Fiber) {
 		return
 	}
 
-	if fiberToDelete.Node != nil {
-		eng := r.engine.(*engine.Engine)
-		eng.Spatial.Delete(fiberToDelete.Node.LastWorldMBR, fiberToDelete.Node.ID)
+	if fiberToDelete.Node != nil && r.host != nil {
+		r.host.RemoveSpatial(fiberToDelete.Node)
 
-		if fiberToDelete.Node.Type == "INPUT" {
-			eng.RemoveDOMOverlay(fiberToDelete.Node.ID)
+		if fiberToDelete.Node.HTMLElementType == scene.Input {
+			r.host.RemoveDOMOverlay(fiberToDelete.Node.ID)
 		}
 	}
 
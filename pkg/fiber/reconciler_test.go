@@ -0,0 +1,65 @@
+package fiber
+
+import (
+	"testing"
+
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+func TestReconciler_Bailout(t *testing.T) {
+	page := newTestNode(1, scene.Page, style.Style{})
+	frame := newTestNode(2, scene.Frame, style.Style{Width: layout.Px(100), Height: layout.Px(100)})
+	a := newTestNode(3, scene.Polygon, style.Style{Left: layout.Px(10), Width: layout.Px(10), Height: layout.Px(10)})
+	other := newTestNode(4, scene.Frame, style.Style{Left: layout.Px(200), Width: layout.Px(50), Height: layout.Px(50)})
+	page.AppendChild(frame)
+	frame.AppendChild(a)
+	b := newTestNode(5, scene.Polygon, style.Style{Width: layout.Px(10), Height: layout.Px(10)})
+	page.AppendChild(other)
+	other.AppendChild(b)
+	page.MarkDirty(scene.FlagLayoutDirty)
+
+	host := &fakeHost{updated: map[uint32]int{}}
+	r := NewReconciler(page, host)
+	r.ScheduleUpdate(page)
+	r.WorkLoop(0)
+
+	for _, node := range []*scene.Node{page, frame, a, other, b} {
+		if node.Flags != scene.FlagNone {
+			t.Errorf("Node %d still dirty after commit: %b", node.ID, node.Flags)
+		}
+	}
+
+	// Nothing changed, the committed children are reused as they are
+	children := r.CurrentRoot.Child
+	r.ScheduleUpdate(page)
+	r.WorkLoop(0)
+	if r.CurrentRoot.Child != children || host.updated[3] != 1 {
+		t.Errorf("Clean update should bail out below the root")
+	}
+
+	// Moving the frame carries its clean child along
+	frame.Style.Left = layout.Px(30)
+	frame.MarkDirty(scene.FlagLayoutDirty)
+	otherFiber := r.CurrentRoot.Child.Sibling.Child
+	r.ScheduleUpdate(page)
+	r.WorkLoop(0)
+
+	aFiber := r.CurrentRoot.Child.Child
+	if x := aFiber.GlobalMatrix[4]; x != 40 {
+		t.Errorf("Expected child of the moved frame at 40, got %v", x)
+	}
+	if host.updated[5] != 1 || r.CurrentRoot.Child.Sibling.Child != otherFiber {
+		t.Errorf("Sibling of the moved frame should bail out")
+	}
+	if frame.Flags != scene.FlagNone || page.Flags != scene.FlagNone {
+		t.Errorf("Edit should be committed clean")
+	}
+
+	// Dirty again, the flag bubbles once more
+	a.MarkDirty(scene.FlagLayoutDirty)
+	if page.Flags&scene.FlagSubtreeDirty == 0 {
+		t.Errorf("MarkDirty should bubble after a commit")
+	}
+}
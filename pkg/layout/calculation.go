@@ -9,3 +9,447 @@ package layout
 
 Flexbox hoạt động theo dòng (Line)
 */
+
+// Compute lays out the descendants of root, root itself keeps
+// its X/Y and gets the given border box size
+func Compute(root *Node, width, height float32) {
+	root.W = width
+	root.H = height
+
+	root.layoutChildren(scrollContext{node: root})
+}
+
+func (n *Node) layoutChildren(ctx scrollContext) {
+	switch n.Mode {
+	case ModeFlex:
+		n.layoutFlex()
+	case ModeGrid:
+		n.layoutGrid()
+	default:
+		n.layoutFree()
+	}
+	n.layoutPositioned(ctx)
+
+	for _, child := range n.Children {
+		child.layoutChildren(ctx.enter(child))
+	}
+}
+
+// layoutFree places in flow children by their own insets and size
+func (n *Node) layoutFree() {
+	for _, child := range n.Children {
+		if child.Position.inFlow() {
+			n.placeFree(child)
+		}
+	}
+}
+
+// flexItem is a child being laid out along the main axis,
+// sizes are border box sizes
+type flexItem struct {
+	node *Node
+
+	basis float32
+	main  float32
+	cross float32
+
+	// Margins along main and cross axis (start, end)
+	mainMargin  [2]float32
+	crossMargin [2]float32
+
+	// Resolved min/max along main and cross axis
+	mainBounds  [2]float32
+	crossBounds [2]float32
+
+	frozen bool
+}
+
+func (it *flexItem) outerMain() float32 {
+	return it.main + it.mainMargin[0] + it.mainMargin[1]
+}
+
+// flexLine is one line of items, cross is the line's cross size
+type flexLine struct {
+	items []*flexItem
+	cross float32
+}
+
+func (n *Node) layoutFlex() {
+	// 1. Main axis / Cross axis
+	row := n.Flex.Direction.isRow()
+	reverse := n.Flex.Direction.isReverse()
+	wrap := n.Flex.Wrap != WrapNone
+	innerW, innerH := n.innerSize()
+
+	innerMain, innerCross := innerW, innerH
+	mainGap, crossGap := n.Flex.ColumnGap, n.Flex.RowGap
+	if !row {
+		innerMain, innerCross = innerH, innerW
+		mainGap, crossGap = crossGap, mainGap
+	}
+
+	// 2. Measure children: flex basis
+	items := n.flexItems(innerW, innerH)
+
+	// Break items into lines by their hypothetical main size
+	lines := breakLines(items, innerMain, mainGap, wrap)
+
+	for _, line := range lines {
+		// 3. flex-grow, flex-shrink, gaps are not free space
+		gaps := mainGap * float32(len(line.items)-1)
+		resolveFlexibleLengths(line.items, innerMain-gaps)
+
+		// Hypothetical cross size now that main size is known,
+		// the line is as tall as its tallest item
+		for _, it := range line.items {
+			it.hypotheticalCross(row, innerCross)
+			line.cross = maxf(line.cross, it.cross+it.crossMargin[0]+it.crossMargin[1])
+		}
+	}
+
+	// A single line container gives its whole cross size to the line
+	if !wrap && len(lines) == 1 {
+		lines[0].cross = innerCross
+	}
+
+	// align-content: distribute remaining cross space between lines
+	crossOffset, crossBetween := float32(0), crossGap
+	if wrap {
+		used := crossGap * float32(len(lines)-1)
+		for _, line := range lines {
+			used += line.cross
+		}
+
+		remaining := innerCross - used
+		if n.Flex.AlignContent == AlignContentStretch {
+			if remaining > 0 {
+				for _, line := range lines {
+					line.cross += remaining / float32(len(lines))
+				}
+			}
+		} else {
+			offset, between := justify(n.Flex.AlignContent.justify(), remaining, len(lines))
+			crossOffset, crossBetween = offset, crossGap+between
+		}
+	}
+
+	// Stretched items fill their line
+	for _, line := range lines {
+		for _, it := range line.items {
+			crossDim := it.node.Height
+			if !row {
+				crossDim = it.node.Width
+			}
+
+			if crossDim.Unit == UnitAuto && it.node.AspectRatio <= 0 && n.Flex.AlignItem == AlignStretch {
+				stretched := maxf(0, line.cross-it.crossMargin[0]-it.crossMargin[1])
+				it.cross = clamp(stretched, it.crossBounds[0], it.crossBounds[1])
+			}
+		}
+	}
+
+	// 4. justify-content per line, 5. positions relative to the content box
+	in := n.inset()
+	lineStart := crossOffset
+	for _, line := range lines {
+		used := mainGap * float32(len(line.items)-1)
+		for _, it := range line.items {
+			used += it.outerMain()
+		}
+		offset, between := justify(n.Flex.JustifyContent, innerMain-used, len(line.items))
+
+		cursor := offset
+		for _, it := range line.items {
+			cursor += it.mainMargin[0]
+			mainPos := cursor
+			cursor += it.main + it.mainMargin[1] + mainGap + between
+
+			crossPos := lineStart + align(n.Flex.AlignItem, line.cross, it.cross, it.crossMargin)
+
+			if reverse {
+				mainPos = innerMain - mainPos - it.main
+			}
+			if n.Flex.Wrap == WrapReverse {
+				crossPos = innerCross - crossPos - it.cross
+			}
+
+			if row {
+				it.node.X, it.node.Y = in.Left+mainPos, in.Top+crossPos
+				it.node.W, it.node.H = it.main, it.cross
+			} else {
+				it.node.X, it.node.Y = in.Left+crossPos, in.Top+mainPos
+				it.node.W, it.node.H = it.cross, it.main
+			}
+		}
+
+		lineStart += line.cross + crossBetween
+	}
+}
+
+// flexItems builds an item per in flow child with its flex basis and
+// hypothetical main size. Items are laid out as if the main axis started
+// at main-start, reverse directions are mirrored at the end.
+func (n *Node) flexItems(innerW, innerH float32) []*flexItem {
+	row := n.Flex.Direction.isRow()
+	innerMain, innerCross := innerW, innerH
+	if !row {
+		innerMain, innerCross = innerH, innerW
+	}
+
+	items := make([]*flexItem, 0, len(n.Children))
+	for _, child := range n.Children {
+		if !child.Position.inFlow() {
+			continue
+		}
+		it := &flexItem{node: child}
+
+		mainDim, crossDim := child.Width, child.Height
+		minW, maxW := child.widthBounds(innerW)
+		minH, maxH := child.heightBounds(innerH)
+		it.mainBounds = [2]float32{minW, maxW}
+		it.crossBounds = [2]float32{minH, maxH}
+		if row {
+			it.mainMargin = [2]float32{child.Margin.Left, child.Margin.Right}
+			it.crossMargin = [2]float32{child.Margin.Top, child.Margin.Bottom}
+		} else {
+			mainDim, crossDim = child.Height, child.Width
+			it.mainBounds, it.crossBounds = it.crossBounds, it.mainBounds
+			it.mainMargin = [2]float32{child.Margin.Top, child.Margin.Bottom}
+			it.crossMargin = [2]float32{child.Margin.Left, child.Margin.Right}
+		}
+		if n.Flex.Direction.isReverse() {
+			it.mainMargin[0], it.mainMargin[1] = it.mainMargin[1], it.mainMargin[0]
+		}
+		if n.Flex.Wrap == WrapReverse {
+			it.crossMargin[0], it.crossMargin[1] = it.crossMargin[1], it.crossMargin[0]
+		}
+
+		switch {
+		case mainDim.Unit != UnitAuto:
+			it.basis = mainDim.Resolve(innerMain)
+		case child.AspectRatio > 0 && crossDim.Unit != UnitAuto:
+			// Basis comes from the fixed cross size through the ratio
+			cross := clamp(crossDim.Resolve(innerCross), it.crossBounds[0], it.crossBounds[1])
+			it.basis = cross * child.AspectRatio
+			if !row {
+				it.basis = cross / child.AspectRatio
+			}
+		default:
+			// Auto items start from their content size
+			mw, mh := child.measure(innerW, innerH)
+			it.basis = mw
+			if !row {
+				it.basis = mh
+			}
+		}
+		// Hypothetical main size
+		it.main = clamp(it.basis, it.mainBounds[0], it.mainBounds[1])
+
+		items = append(items, it)
+	}
+
+	return items
+}
+
+// hypotheticalCross sizes it on the cross axis from its main size
+func (it *flexItem) hypotheticalCross(row bool, innerCross float32) {
+	child := it.node
+	crossDim := child.Height
+	if !row {
+		crossDim = child.Width
+	}
+
+	switch {
+	case crossDim.Unit != UnitAuto:
+		it.cross = crossDim.Resolve(innerCross)
+	case child.AspectRatio > 0:
+		// Ratio wins over stretch, like a locked ratio in Figma
+		it.cross = it.main / child.AspectRatio
+		if !row {
+			it.cross = it.main * child.AspectRatio
+		}
+	case row:
+		_, it.cross = child.measure(it.main, innerCross)
+	default:
+		it.cross, _ = child.measure(innerCross, it.main)
+	}
+	it.cross = clamp(it.cross, it.crossBounds[0], it.crossBounds[1])
+}
+
+// breakLines collects items into lines, a new line starts when the next
+// item would overflow innerMain. Every line has at least one item.
+func breakLines(items []*flexItem, innerMain, gap float32, wrap bool) []*flexLine {
+	if !wrap {
+		return []*flexLine{{items: items}}
+	}
+
+	var lines []*flexLine
+	var line *flexLine
+	used := float32(0)
+	for _, it := range items {
+		size := it.outerMain()
+		if line == nil || used+gap+size > innerMain {
+			line = &flexLine{}
+			lines = append(lines, line)
+			used = size
+		} else {
+			used += gap + size
+		}
+		line.items = append(line.items, it)
+	}
+
+	if lines == nil {
+		lines = []*flexLine{{}}
+	}
+	return lines
+}
+
+// resolveFlexibleLengths grows or shrinks items to fill innerMain,
+// following the CSS "resolve flexible lengths" loop: targets are clamped
+// by min/max, the items of the dominant violation are frozen and the
+// free space is distributed again among the rest.
+func resolveFlexibleLengths(items []*flexItem, innerMain float32) {
+	// Grow or shrink is decided on the hypothetical sizes
+	used := float32(0)
+	for _, it := range items {
+		used += it.outerMain()
+	}
+	growing := innerMain > used
+
+	// Inflexible items keep their hypothetical size
+	for _, it := range items {
+		flex := it.node.Flex.Shrink
+		if growing {
+			flex = it.node.Flex.Grow
+		}
+		it.frozen = flex <= 0 || (!growing && innerMain >= used) ||
+			(growing && it.basis > it.main) || (!growing && it.basis < it.main)
+	}
+
+	violations := make([]float32, len(items))
+	for {
+		free := innerMain
+		factor := float32(0)
+		flexible := false
+		for _, it := range items {
+			if it.frozen {
+				free -= it.outerMain()
+				continue
+			}
+
+			flexible = true
+			free -= it.basis + it.mainMargin[0] + it.mainMargin[1]
+			if growing {
+				factor += float32(it.node.Flex.Grow)
+			} else {
+				// Shrink is weighted by basis so big items shrink more
+				factor += float32(it.node.Flex.Shrink) * it.basis
+			}
+		}
+
+		if !flexible {
+			return
+		}
+
+		total := float32(0)
+		for i, it := range items {
+			if it.frozen {
+				continue
+			}
+
+			target := it.basis
+			if factor > 0 {
+				if growing {
+					target += free * float32(it.node.Flex.Grow) / factor
+				} else {
+					target += free * float32(it.node.Flex.Shrink) * it.basis / factor
+				}
+			}
+
+			it.main = clamp(maxf(0, target), it.mainBounds[0], it.mainBounds[1])
+			violations[i] = it.main - target
+			total += violations[i]
+		}
+
+		// Positive total: items were held by their min, freeze those.
+		// Negative: held by their max. Zero: every size is final.
+		for i, it := range items {
+			if it.frozen {
+				continue
+			}
+			if total == 0 || (total > 0 && violations[i] > 0) || (total < 0 && violations[i] < 0) {
+				it.frozen = true
+			}
+		}
+	}
+}
+
+// justify returns the offset of the first item and the extra
+// space between items for remaining free space
+func justify(j JustifyContent, remaining float32, count int) (float32, float32) {
+	if count == 0 {
+		return 0, 0
+	}
+
+	// Like CSS: when items overflow, space-* fall back to start or center
+	if remaining < 0 {
+		switch j {
+		case JustifyBetween:
+			j = JustifyStart
+		case JustifyAround, JustifyEvenly:
+			j = JustifyCenter
+		}
+	}
+
+	n := float32(count)
+	switch j {
+	case JustifyEnd:
+		return remaining, 0
+	case JustifyCenter:
+		return remaining / 2, 0
+	case JustifyBetween:
+		if count == 1 {
+			return 0, 0
+		}
+		return 0, remaining / (n - 1)
+	case JustifyAround:
+		return remaining / n / 2, remaining / n
+	case JustifyEvenly:
+		return remaining / (n + 1), remaining / (n + 1)
+	}
+
+	return 0, 0
+}
+
+// align returns the cross offset of an item inside the line
+func align(a AlignItem, lineCross, cross float32, margin [2]float32) float32 {
+	free := lineCross - cross - margin[0] - margin[1]
+
+	switch a {
+	case AlignCenter:
+		return margin[0] + free/2
+	case AlignEnd:
+		return margin[0] + free
+	}
+
+	return margin[0]
+}
+
+// justify maps align-content onto the equivalent justify-content,
+// lines are distributed on the cross axis the same way items are
+// on the main axis
+func (a AlignContent) justify() JustifyContent {
+	switch a {
+	case AlignContentEnd:
+		return JustifyEnd
+	case AlignContentCenter:
+		return JustifyCenter
+	case AlignContentBetween:
+		return JustifyBetween
+	case AlignContentAround:
+		return JustifyAround
+	case AlignContentEvenly:
+		return JustifyEvenly
+	}
+	return JustifyStart
+}
@@ -0,0 +1,81 @@
+package layout
+
+import "testing"
+
+func TestConstraints_Free(t *testing.T) {
+	root := &Node{Children: []*Node{
+		{Width: Px(500), Height: Px(10), MaxWidth: Pct(50)},
+		// min wins over max
+		{Width: Px(10), Height: Px(10), MinWidth: Px(40), MaxWidth: Px(20)},
+		{Width: Px(120), Height: Auto(), AspectRatio: 1.5},
+		{Width: Auto(), Height: Px(30), AspectRatio: 2},
+		// The ratio gives 200, clamped by the max
+		{Width: Px(100), Height: Auto(), AspectRatio: 0.5, MaxHeight: Px(150)},
+	}}
+	Compute(root, 400, 400)
+
+	assertBoxes(t, "free", root, []box{
+		{0, 0, 200, 10},
+		{0, 0, 40, 10},
+		{0, 0, 120, 80},
+		{0, 0, 60, 30},
+		{0, 0, 100, 150},
+	})
+}
+
+func TestConstraints_FlexGrow(t *testing.T) {
+	// 400 / 3 is over the max of the first item, it freezes
+	// at 50 and the others share the remaining 350
+	capped := grow(1, 10)
+	capped.MaxWidth = Px(50)
+
+	root := container(Flex{AlignItem: AlignStart}, 0, capped, grow(1, 10), grow(1, 10))
+	Compute(root, 400, 100)
+	assertBoxes(t, "grow max", root, []box{{0, 0, 50, 10}, {50, 0, 175, 10}, {225, 0, 175, 10}})
+
+	// The hypothetical size of an inflexible item already respects its min
+	floor := fixed(0, 10)
+	floor.MinWidth = Px(100)
+
+	root = container(Flex{AlignItem: AlignStart}, 0, floor, grow(1, 10))
+	Compute(root, 300, 100)
+	assertBoxes(t, "hypothetical min", root, []box{{0, 0, 100, 10}, {100, 0, 200, 10}})
+}
+
+func TestConstraints_FlexShrink(t *testing.T) {
+	shrinking := func(w float32) *Node {
+		n := fixed(w, 20)
+		n.Flex.Shrink = 1
+		return n
+	}
+
+	// Both would shrink to 150, the first one stops at its min
+	// and the second one takes the rest of the overflow
+	first := shrinking(200)
+	first.MinWidth = Px(180)
+
+	root := container(Flex{AlignItem: AlignStart}, 0, first, shrinking(200))
+	Compute(root, 300, 100)
+	assertBoxes(t, "shrink min", root, []box{{0, 0, 180, 20}, {180, 0, 120, 20}})
+}
+
+func TestConstraints_FlexAspectRatio(t *testing.T) {
+	root := container(Flex{}, 0,
+		// Basis from the fixed height
+		&Node{Width: Auto(), Height: Px(50), AspectRatio: 2},
+		// Ratio wins over stretch
+		&Node{Width: Px(80), Height: Auto(), AspectRatio: 2},
+		// Stretch is clamped
+		&Node{Width: Px(50), Height: Auto(), MaxHeight: Px(60)})
+	Compute(root, 300, 100)
+
+	assertBoxes(t, "aspect ratio", root, []box{{0, 0, 100, 50}, {100, 0, 80, 40}, {180, 0, 50, 60}})
+}
+
+func TestConstraints_Grid(t *testing.T) {
+	root := gridContainer(Grid{Columns: []Track{PxTrack(100)}, Rows: []Track{PxTrack(100)}}, 0,
+		&Node{Width: Auto(), Height: Auto(), MaxWidth: Px(60), MaxHeight: Auto()})
+	Compute(root, 100, 100)
+
+	assertBoxes(t, "grid stretch max", root, []box{{0, 0, 60, 100}})
+}
@@ -46,21 +46,20 @@ func (d Dimension) Resolve(parentSize float32) float32 {
 	case UnitPercent:
 		return (d.Value / 100.0) * parentSize
 	case UnitAuto:
-		// Với Auto, thường Layout Engine sẽ xử lý riêng.
-		// Nhưng nếu cần số, Auto thường mặc định là 0 hoặc content-size.
-		// FIXME: should consider about this
+		// Auto không có giá trị cố định: layout pass dùng kích thước nội dung
+		// (Measure của leaf, hoặc hug các con), xem Node.measure
 		return 0
 	}
 	return 0
 }
 
 // UnmarshalJSON: Parse dữ liệu từ JS/File
-// Hỗ trợ: 100 (số), "100px", "50%", "auto"
+// Hỗ trợ: 100 (số), "100px", "50%", "auto", "none"
 func (d *Dimension) UnmarshalJSON(data []byte) error {
 	str := string(data)
 
-	// Trường hợp 1: "auto"
-	if strings.Contains(str, "auto") {
+	// Trường hợp 1: "auto" ("none" của min/max cũng là auto)
+	if strings.Contains(str, "auto") || strings.Contains(str, "none") {
 		d.Unit = UnitAuto
 		d.Value = 0
 		return nil
@@ -1,10 +1,43 @@
 package layout
 
+// Mode chooses how a container places its children
+type Mode int
+
+const (
+	// Children are placed by their Left/Top, like a Figma
+	// frame without auto layout
+	ModeNone Mode = iota
+	ModeFlex
+	ModeGrid
+)
+
 type Direction int
 
 const (
 	DirectionRow Direction = iota
 	DirectionColumn
+	// Same axis, items start from the end of the main axis
+	DirectionRowReverse
+	DirectionColumnReverse
+)
+
+func (d Direction) isRow() bool {
+	return d == DirectionRow || d == DirectionRowReverse
+}
+
+func (d Direction) isReverse() bool {
+	return d == DirectionRowReverse || d == DirectionColumnReverse
+}
+
+type Wrap int
+
+const (
+	// All items stay on one line and shrink to fit
+	WrapNone Wrap = iota
+	// Items break into new lines on the cross axis
+	WrapLine
+	// Like WrapLine, but lines stack from the cross axis end
+	WrapReverse
 )
 
 type JustifyContent int
@@ -33,3 +66,18 @@ const (
 	AlignStart
 	AlignEnd
 )
+
+// AlignContent distributes the lines of a wrapping flex container
+// on the cross axis, it has no effect on a single line container
+type AlignContent int
+
+const (
+	// Lines share the remaining space, like CSS "normal"
+	AlignContentStretch AlignContent = iota
+	AlignContentStart
+	AlignContentEnd
+	AlignContentCenter
+	AlignContentBetween
+	AlignContentAround
+	AlignContentEvenly
+)
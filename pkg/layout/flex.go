@@ -6,9 +6,17 @@ package layout
 // struct for flex layout property
 // TODO: Using bitmap for this
 type Flex struct {
-	Direction      Direction
-	JustifyContent JustifyContent
-	AlignItem      AlignItem
-	Grow           int8
-	Shrink         int8
+	Direction      Direction      `json:"direction"`
+	JustifyContent JustifyContent `json:"justifyContent"`
+	AlignItem      AlignItem      `json:"alignItems"`
+	Grow           int8           `json:"grow"`
+	Shrink         int8           `json:"shrink"`
+
+	Wrap         Wrap         `json:"wrap"`
+	AlignContent AlignContent `json:"alignContent"`
+	// Space between rows and between columns, in pixel.
+	// In a row container ColumnGap separates items and
+	// RowGap separates lines, the other way around in a column.
+	RowGap    float32 `json:"rowGap"`
+	ColumnGap float32 `json:"columnGap"`
 }
@@ -0,0 +1,273 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+type box struct {
+	X, Y, W, H float32
+}
+
+func fixed(w, h float32) *Node {
+	return &Node{Width: Px(w), Height: Px(h)}
+}
+
+func grow(g int8, h float32) *Node {
+	return &Node{Width: Px(0), Height: Px(h), Flex: Flex{Grow: g}}
+}
+
+func container(flex Flex, padding float32, children ...*Node) *Node {
+	return &Node{
+		Mode:     ModeFlex,
+		Flex:     flex,
+		Padding:  Uniform(padding),
+		Children: children,
+	}
+}
+
+func assertBoxes(t *testing.T, name string, root *Node, expected []box) {
+	t.Helper()
+
+	if len(root.Children) != len(expected) {
+		t.Fatalf("%s: expected %d children, got %d", name, len(expected), len(root.Children))
+	}
+
+	const eps = 0.01
+	for i, child := range root.Children {
+		got := box{child.X, child.Y, child.W, child.H}
+		want := expected[i]
+		if math.Abs(float64(got.X-want.X)) > eps || math.Abs(float64(got.Y-want.Y)) > eps ||
+			math.Abs(float64(got.W-want.W)) > eps || math.Abs(float64(got.H-want.H)) > eps {
+			t.Errorf("%s: child %d expected %+v, got %+v", name, i, want, got)
+		}
+	}
+}
+
+// Horizontal auto layout frame, 400x100 with 10px padding, so the
+// content box is 380x80 starting at (10, 10)
+func TestFlex_Justify(t *testing.T) {
+	cases := []struct {
+		name     string
+		justify  JustifyContent
+		expected []box
+	}{
+		// 3 items of 60 = 180, remaining 200
+		{"start", JustifyStart, []box{{10, 10, 60, 40}, {70, 10, 60, 40}, {130, 10, 60, 40}}},
+		{"end", JustifyEnd, []box{{210, 10, 60, 40}, {270, 10, 60, 40}, {330, 10, 60, 40}}},
+		{"center", JustifyCenter, []box{{110, 10, 60, 40}, {170, 10, 60, 40}, {230, 10, 60, 40}}},
+		{"between", JustifyBetween, []box{{10, 10, 60, 40}, {170, 10, 60, 40}, {330, 10, 60, 40}}},
+		// 200 / 3 = 66.67 around each item
+		{"around", JustifyAround, []box{{43.33, 10, 60, 40}, {170, 10, 60, 40}, {296.67, 10, 60, 40}}},
+		// 200 / 4 = 50 between and around
+		{"evenly", JustifyEvenly, []box{{60, 10, 60, 40}, {170, 10, 60, 40}, {280, 10, 60, 40}}},
+	}
+
+	for _, c := range cases {
+		root := container(Flex{JustifyContent: c.justify, AlignItem: AlignStart}, 10,
+			fixed(60, 40), fixed(60, 40), fixed(60, 40))
+		Compute(root, 400, 100)
+		assertBoxes(t, c.name, root, c.expected)
+	}
+}
+
+func TestFlex_Align(t *testing.T) {
+	cases := []struct {
+		name     string
+		align    AlignItem
+		expected []box
+	}{
+		{"start", AlignStart, []box{{10, 10, 60, 40}, {70, 10, 60, 0}}},
+		{"center", AlignCenter, []box{{10, 30, 60, 40}, {70, 50, 60, 0}}},
+		{"end", AlignEnd, []box{{10, 50, 60, 40}, {70, 90, 60, 0}}},
+		// Fixed height is kept, auto height fills the line
+		{"stretch", AlignStretch, []box{{10, 10, 60, 40}, {70, 10, 60, 80}}},
+	}
+
+	for _, c := range cases {
+		root := container(Flex{AlignItem: c.align}, 10,
+			fixed(60, 40), &Node{Width: Px(60), Height: Auto()})
+		Compute(root, 400, 100)
+		assertBoxes(t, c.name, root, c.expected)
+	}
+}
+
+func TestFlex_Grow(t *testing.T) {
+	// "Fill container" items share 380 - 80 = 300 by 1:2
+	root := container(Flex{AlignItem: AlignStart}, 10,
+		fixed(80, 20), grow(1, 20), grow(2, 20))
+	Compute(root, 400, 100)
+
+	assertBoxes(t, "grow", root, []box{{10, 10, 80, 20}, {90, 10, 100, 20}, {190, 10, 200, 20}})
+}
+
+func TestFlex_Shrink(t *testing.T) {
+	shrinking := func(w float32) *Node {
+		n := fixed(w, 20)
+		n.Flex.Shrink = 1
+		return n
+	}
+
+	// 100 + 200 + 180 = 480 in 380, 100 over, shared by the shrinkable
+	// items weighted by basis: 100 - 100*100/300, 200 - 100*200/300
+	root := container(Flex{AlignItem: AlignStart}, 10,
+		shrinking(100), shrinking(200), fixed(180, 20))
+	Compute(root, 400, 100)
+
+	assertBoxes(t, "shrink", root, []box{
+		{10, 10, 66.67, 20},
+		{76.67, 10, 133.33, 20},
+		{210, 10, 180, 20},
+	})
+
+	// Can't shrink below 0
+	root = container(Flex{AlignItem: AlignStart}, 0, shrinking(10), fixed(500, 20))
+	Compute(root, 100, 100)
+	assertBoxes(t, "shrink clamp", root, []box{{0, 0, 0, 20}, {0, 0, 500, 20}})
+}
+
+func TestFlex_Column(t *testing.T) {
+	// Vertical auto layout, fill width, items hug the top
+	root := container(Flex{Direction: DirectionColumn, JustifyContent: JustifyStart}, 16,
+		&Node{Width: Auto(), Height: Px(40)},
+		&Node{Width: Pct(50), Height: Px(40), Margin: Edges{Top: 8}},
+		&Node{Width: Auto(), Height: Px(0), Flex: Flex{Grow: 1}})
+	Compute(root, 200, 300)
+
+	assertBoxes(t, "column", root, []box{
+		{16, 16, 168, 40},
+		{16, 64, 84, 40},
+		// 268 - 40 - 48 = 180 left for the growing item
+		{16, 104, 168, 180},
+	})
+}
+
+func TestFlex_OverflowFallback(t *testing.T) {
+	// Items overflow: space-between acts like start, space-evenly like center
+	root := container(Flex{JustifyContent: JustifyBetween, AlignItem: AlignStart}, 0, fixed(80, 10), fixed(80, 10))
+	Compute(root, 100, 10)
+	assertBoxes(t, "between overflow", root, []box{{0, 0, 80, 10}, {80, 0, 80, 10}})
+
+	root = container(Flex{JustifyContent: JustifyEvenly, AlignItem: AlignStart}, 0, fixed(80, 10), fixed(80, 10))
+	Compute(root, 100, 10)
+	assertBoxes(t, "evenly overflow", root, []box{{-30, 0, 80, 10}, {50, 0, 80, 10}})
+}
+
+func TestFlex_Reverse(t *testing.T) {
+	second := fixed(80, 20)
+	second.Margin.Left = 5
+
+	// Items start from the right, the left margin stays on the left
+	root := container(Flex{Direction: DirectionRowReverse, AlignItem: AlignStart}, 10, fixed(60, 40), second)
+	Compute(root, 400, 100)
+	assertBoxes(t, "row-reverse", root, []box{{330, 10, 60, 40}, {250, 10, 80, 20}})
+
+	// justify end of a reversed column packs items at the top
+	root = container(Flex{Direction: DirectionColumnReverse, JustifyContent: JustifyEnd, AlignItem: AlignStart}, 0,
+		fixed(20, 30), fixed(20, 50))
+	Compute(root, 100, 200)
+	assertBoxes(t, "column-reverse", root, []box{{0, 50, 20, 30}, {0, 0, 20, 50}})
+}
+
+func TestFlex_Gap(t *testing.T) {
+	root := container(Flex{AlignItem: AlignStart, ColumnGap: 10, RowGap: 99}, 10,
+		fixed(60, 40), fixed(60, 40), fixed(60, 40))
+	Compute(root, 400, 100)
+	assertBoxes(t, "gap", root, []box{{10, 10, 60, 40}, {80, 10, 60, 40}, {150, 10, 60, 40}})
+
+	// Gaps are not free space: 380 - 180 - 20 = 180 between 2 spaces
+	root.Flex.JustifyContent = JustifyBetween
+	Compute(root, 400, 100)
+	assertBoxes(t, "gap between", root, []box{{10, 10, 60, 40}, {170, 10, 60, 40}, {330, 10, 60, 40}})
+
+	root = container(Flex{AlignItem: AlignStart, ColumnGap: 20}, 10, grow(1, 20), grow(1, 20))
+	Compute(root, 400, 100)
+	assertBoxes(t, "gap grow", root, []box{{10, 10, 180, 20}, {210, 10, 180, 20}})
+}
+
+func TestFlex_Wrap(t *testing.T) {
+	// Chip list: 2 chips of 100 + 10 gap per 300px line
+	root := container(Flex{
+		Wrap:         WrapLine,
+		AlignItem:    AlignStart,
+		AlignContent: AlignContentStart,
+		ColumnGap:    10,
+		RowGap:       5,
+	}, 0, fixed(100, 40), fixed(100, 40), fixed(100, 60), fixed(100, 40), fixed(100, 40))
+	Compute(root, 300, 200)
+
+	assertBoxes(t, "wrap", root, []box{
+		{0, 0, 100, 40}, {110, 0, 100, 40},
+		// The second line is as tall as its tallest item
+		{0, 45, 100, 60}, {110, 45, 100, 40},
+		{0, 110, 100, 40},
+	})
+
+	// Lines stack from the bottom
+	root = container(Flex{Wrap: WrapReverse, AlignItem: AlignStart, AlignContent: AlignContentStart}, 0,
+		fixed(120, 40), fixed(120, 40))
+	Compute(root, 200, 100)
+	assertBoxes(t, "wrap-reverse", root, []box{{0, 60, 120, 40}, {0, 20, 120, 40}})
+}
+
+func TestFlex_AlignContent(t *testing.T) {
+	// 3 lines of 40 in 200, 80 left on the cross axis
+	cases := []struct {
+		name     string
+		align    AlignContent
+		expected []float32
+	}{
+		{"stretch", AlignContentStretch, []float32{0, 66.67, 133.33}},
+		{"start", AlignContentStart, []float32{0, 40, 80}},
+		{"end", AlignContentEnd, []float32{80, 120, 160}},
+		{"center", AlignContentCenter, []float32{40, 80, 120}},
+		{"between", AlignContentBetween, []float32{0, 80, 160}},
+		{"evenly", AlignContentEvenly, []float32{20, 80, 140}},
+	}
+
+	for _, c := range cases {
+		root := container(Flex{Wrap: WrapLine, AlignItem: AlignStart, AlignContent: c.align}, 0,
+			fixed(120, 40), fixed(120, 40), fixed(120, 40))
+		Compute(root, 200, 200)
+
+		expected := make([]box, len(c.expected))
+		for i, y := range c.expected {
+			expected[i] = box{0, y, 120, 40}
+		}
+		assertBoxes(t, c.name, root, expected)
+	}
+
+	// Stretched items fill their stretched line
+	auto := func() *Node { return &Node{Width: Px(120), Height: Auto()} }
+	root := container(Flex{Wrap: WrapLine}, 0, auto(), auto(), auto())
+	Compute(root, 200, 200)
+	assertBoxes(t, "stretch items", root, []box{{0, 0, 120, 66.67}, {0, 66.67, 120, 66.67}, {0, 133.33, 120, 66.67}})
+}
+
+func TestFlex_MeasureAndNesting(t *testing.T) {
+	label := &Node{
+		Width:  Auto(),
+		Height: Auto(),
+		Measure: func(w, h float32) (float32, float32) {
+			return 120, 18
+		},
+	}
+
+	inner := container(Flex{AlignItem: AlignCenter}, 8, fixed(24, 24), label)
+	inner.Width, inner.Height = Px(200), Px(40)
+
+	root := container(Flex{Direction: DirectionColumn, AlignItem: AlignStart}, 0, inner)
+	Compute(root, 300, 300)
+
+	assertBoxes(t, "outer", root, []box{{0, 0, 200, 40}})
+	assertBoxes(t, "inner", inner, []box{{8, 8, 24, 24}, {32, 11, 120, 18}})
+}
+
+func TestFree_Layout(t *testing.T) {
+	root := &Node{Children: []*Node{
+		{Left: Px(10), Top: Px(20), Width: Px(30), Height: Pct(50)},
+	}}
+	Compute(root, 100, 100)
+
+	assertBoxes(t, "free", root, []box{{10, 20, 30, 50}})
+}
@@ -1 +1,20 @@
 package layout
+
+// clamp bounds v to [lo, hi], lo wins when hi < lo like CSS
+func clamp(v, lo, hi float32) float32 {
+	return maxf(lo, minf(v, hi))
+}
+
+func minf(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxf(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,463 @@
+package layout
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TrackUnit is the sizing function of a grid track
+type TrackUnit uint8
+
+const (
+	TrackPixel   TrackUnit = 0
+	TrackPercent TrackUnit = 1
+	// Share of the space left by the other tracks
+	TrackFr TrackUnit = 2
+	// As large as the biggest item in the track
+	TrackAuto TrackUnit = 3
+)
+
+// Track is one row or column of a grid template
+type Track struct {
+	Value float32
+	Unit  TrackUnit
+}
+
+func PxTrack(v float32) Track {
+	return Track{Value: v, Unit: TrackPixel}
+}
+
+func PctTrack(v float32) Track {
+	return Track{Value: v, Unit: TrackPercent}
+}
+
+func Fr(v float32) Track {
+	return Track{Value: v, Unit: TrackFr}
+}
+
+func AutoTrack() Track {
+	return Track{Unit: TrackAuto}
+}
+
+// UnmarshalJSON accepts the Dimension formats plus "1fr"
+func (t *Track) UnmarshalJSON(data []byte) error {
+	str := string(data)
+
+	if strings.HasSuffix(str, `fr"`) {
+		val, err := strconv.ParseFloat(strings.TrimSuffix(strings.Trim(str, `"`), "fr"), 32)
+		if err != nil {
+			return err
+		}
+		*t = Fr(float32(val))
+		return nil
+	}
+
+	var d Dimension
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+
+	switch d.Unit {
+	case UnitPercent:
+		*t = PctTrack(d.Value)
+	case UnitAuto:
+		*t = AutoTrack()
+	default:
+		*t = PxTrack(d.Value)
+	}
+	return nil
+}
+
+func (t Track) MarshalJSON() ([]byte, error) {
+	switch t.Unit {
+	case TrackFr:
+		return []byte(fmt.Sprintf(`"%.2ffr"`, t.Value)), nil
+	case TrackPercent:
+		return Pct(t.Value).MarshalJSON()
+	case TrackAuto:
+		return Auto().MarshalJSON()
+	}
+	return Px(t.Value).MarshalJSON()
+}
+
+// Grid holds the container properties of a ModeGrid node
+type Grid struct {
+	// Explicit tracks, items placed outside of them
+	// get implicit auto tracks
+	Columns []Track `json:"columns,omitempty"`
+	Rows    []Track `json:"rows,omitempty"`
+
+	RowGap    float32 `json:"rowGap"`
+	ColumnGap float32 `json:"columnGap"`
+
+	// Alignment of items inside their grid area,
+	// horizontally and vertically
+	JustifyItems AlignItem `json:"justifyItems"`
+	AlignItems   AlignItem `json:"alignItems"`
+}
+
+// Clone copies the track slices, so edits of the clone
+// don't leak into the source
+func (g Grid) Clone() Grid {
+	if g.Columns != nil {
+		g.Columns = append([]Track(nil), g.Columns...)
+	}
+	if g.Rows != nil {
+		g.Rows = append([]Track(nil), g.Rows...)
+	}
+	return g
+}
+
+// IsZero reports whether g holds only default values
+func (g Grid) IsZero() bool {
+	return len(g.Columns) == 0 && len(g.Rows) == 0 &&
+		g.RowGap == 0 && g.ColumnGap == 0 &&
+		g.JustifyItems == AlignStretch && g.AlignItems == AlignStretch
+}
+
+// GridItem places a node inside a grid parent
+type GridItem struct {
+	// 1-based start line, 0 lets auto-placement choose
+	Column int32 `json:"column"`
+	Row    int32 `json:"row"`
+
+	// Number of tracks covered, 0 is the same as 1
+	ColumnSpan int32 `json:"columnSpan"`
+	RowSpan    int32 `json:"rowSpan"`
+}
+
+// maxGridLines bounds explicit lines and spans like CSS does, a
+// broken document must not size the placement grid
+const maxGridLines = 10000
+
+// gridArea is the cell range of one item, 0-based
+type gridArea struct {
+	node *Node
+
+	col, row         int
+	colSpan, rowSpan int
+}
+
+func (n *Node) layoutGrid() {
+	innerW, innerH := n.innerSize()
+	areas, cols, rows := n.gridTracks(innerW, innerH, innerW, innerH)
+	colPos := trackPositions(cols, n.inset().Left, n.Grid.ColumnGap)
+	rowPos := trackPositions(rows, n.inset().Top, n.Grid.RowGap)
+
+	// Size and align each item inside its area
+	for _, a := range areas {
+		child := a.node
+		areaW := spanSize(cols, a.col, a.colSpan, n.Grid.ColumnGap)
+		areaH := spanSize(rows, a.row, a.rowSpan, n.Grid.RowGap)
+		availW := maxf(0, areaW-child.Margin.Horizontal())
+		availH := maxf(0, areaH-child.Margin.Vertical())
+
+		child.W, child.H = child.resolveBox(areaW, areaH, availW, availH,
+			n.Grid.JustifyItems == AlignStretch, n.Grid.AlignItems == AlignStretch)
+
+		child.X = colPos[a.col] + align(n.Grid.JustifyItems, areaW, child.W,
+			[2]float32{child.Margin.Left, child.Margin.Right})
+		child.Y = rowPos[a.row] + align(n.Grid.AlignItems, areaH, child.H,
+			[2]float32{child.Margin.Top, child.Margin.Bottom})
+	}
+}
+
+// gridTracks places the items and sizes the tracks. Items measure
+// against innerW x innerH, tracks share trackW x trackH: with 0 the
+// fr and auto tracks only fit their content.
+func (n *Node) gridTracks(innerW, innerH, trackW, trackH float32) ([]*gridArea, []float32, []float32) {
+	areas, colCount, rowCount := n.placeGridItems()
+
+	// Columns first, rows need the column widths to measure items
+	colSpans := make([]trackSpan, len(areas))
+	for i, a := range areas {
+		w := a.node.Width.Resolve(innerW)
+		if a.node.Width.Unit == UnitAuto {
+			w, _ = a.node.measure(innerW, innerH)
+		}
+		minW, maxW := a.node.widthBounds(innerW)
+		w = clamp(w, minW, maxW)
+		colSpans[i] = trackSpan{a.col, a.colSpan, w + a.node.Margin.Horizontal()}
+	}
+	cols := resolveTracks(n.Grid.Columns, colCount, trackW, n.Grid.ColumnGap, colSpans)
+
+	rowSpans := make([]trackSpan, len(areas))
+	for i, a := range areas {
+		h := a.node.Height.Resolve(innerH)
+		if a.node.Height.Unit == UnitAuto {
+			areaW := spanSize(cols, a.col, a.colSpan, n.Grid.ColumnGap)
+			_, h = a.node.measure(maxf(0, areaW-a.node.Margin.Horizontal()), innerH)
+		}
+		minH, maxH := a.node.heightBounds(innerH)
+		h = clamp(h, minH, maxH)
+		rowSpans[i] = trackSpan{a.row, a.rowSpan, h + a.node.Margin.Vertical()}
+	}
+	rows := resolveTracks(n.Grid.Rows, rowCount, trackH, n.Grid.RowGap, rowSpans)
+
+	return areas, cols, rows
+}
+
+// placeGridItems assigns every child a grid area and returns the
+// number of columns and rows. Like CSS, items with both lines set are
+// placed first, then items locked to a row, then the rest flows row
+// by row in document order without going back (sparse packing).
+func (n *Node) placeGridItems() ([]*gridArea, int, int) {
+	areas := make([]*gridArea, 0, len(n.Children))
+
+	colCount := max(len(n.Grid.Columns), 1)
+	for _, child := range n.Children {
+		if !child.Position.inFlow() {
+			continue
+		}
+		p := child.GridItem
+		a := &gridArea{node: child}
+		a.col, a.colSpan = gridLines(p.Column, p.ColumnSpan)
+		a.row, a.rowSpan = gridLines(p.Row, p.RowSpan)
+		if a.col >= 0 {
+			colCount = max(colCount, a.col+a.colSpan)
+		} else {
+			colCount = max(colCount, a.colSpan)
+		}
+		areas = append(areas, a)
+	}
+
+	var occupied [][]bool
+	fits := func(a *gridArea, row, col int) bool {
+		if col+a.colSpan > colCount {
+			return false
+		}
+		for r := row; r < row+a.rowSpan && r < len(occupied); r++ {
+			for c := col; c < col+a.colSpan; c++ {
+				if occupied[r][c] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	occupy := func(a *gridArea, row, col int) {
+		a.row, a.col = row, col
+		for len(occupied) < row+a.rowSpan {
+			occupied = append(occupied, make([]bool, colCount))
+		}
+		for r := row; r < row+a.rowSpan; r++ {
+			for c := col; c < col+a.colSpan; c++ {
+				occupied[r][c] = true
+			}
+		}
+	}
+
+	// Fully explicit items may overlap, they are not moved
+	for _, a := range areas {
+		if a.row >= 0 && a.col >= 0 {
+			occupy(a, a.row, a.col)
+		}
+	}
+
+	// Row locked items take the first free columns of their row
+	for _, a := range areas {
+		if a.row >= 0 && a.col < 0 {
+			col := 0
+			for col+a.colSpan < colCount && !fits(a, a.row, col) {
+				col++
+			}
+			occupy(a, a.row, col)
+		}
+	}
+
+	cursorRow, cursorCol := 0, 0
+	for _, a := range areas {
+		if a.row >= 0 {
+			continue
+		}
+
+		if a.col >= 0 {
+			// Column locked: next row where the column is free
+			if a.col < cursorCol {
+				cursorRow++
+			}
+			for !fits(a, cursorRow, a.col) {
+				cursorRow++
+			}
+			occupy(a, cursorRow, a.col)
+			cursorCol = a.col + a.colSpan
+			continue
+		}
+
+		for !fits(a, cursorRow, cursorCol) {
+			cursorCol++
+			if cursorCol+a.colSpan > colCount {
+				cursorRow++
+				cursorCol = 0
+			}
+		}
+		occupy(a, cursorRow, cursorCol)
+		cursorCol += a.colSpan
+	}
+
+	rowCount := max(len(n.Grid.Rows), len(occupied), 1)
+	return areas, colCount, rowCount
+}
+
+// gridLines turns a 1-based line and a span into a 0-based start,
+// negative for auto, and a span that stays inside maxGridLines
+func gridLines(line, span int32) (int, int) {
+	start := int(min(line, maxGridLines)) - 1
+	n := int(max(1, min(span, maxGridLines)))
+	if start >= 0 {
+		n = min(n, maxGridLines-start)
+	}
+	return start, n
+}
+
+// trackSpan is the outer size an item needs from the tracks it covers
+type trackSpan struct {
+	start, span int
+	size        float32
+}
+
+// resolveTracks sizes count tracks sharing innerSize. Tracks past
+// the template are implicit auto tracks.
+func resolveTracks(template []Track, count int, innerSize, gap float32, spans []trackSpan) []float32 {
+	tracks := make([]Track, count)
+	for i := range tracks {
+		tracks[i] = AutoTrack()
+		if i < len(template) {
+			tracks[i] = template[i]
+		}
+	}
+
+	// Base sizes: fixed tracks resolve, auto and fr tracks fit
+	// the items that span only them
+	sizes := make([]float32, count)
+	for i, t := range tracks {
+		switch t.Unit {
+		case TrackPixel:
+			sizes[i] = t.Value
+		case TrackPercent:
+			sizes[i] = t.Value / 100 * innerSize
+		}
+	}
+	for _, s := range spans {
+		unit := tracks[s.start].Unit
+		if s.span == 1 && (unit == TrackAuto || unit == TrackFr) {
+			sizes[s.start] = maxf(sizes[s.start], s.size)
+		}
+	}
+
+	// Spanning items grow the auto tracks they cover, unless
+	// a fr track can take the extra space later
+	for _, s := range spans {
+		if s.span == 1 {
+			continue
+		}
+
+		var autos []int
+		flexible := false
+		for i := s.start; i < s.start+s.span; i++ {
+			switch tracks[i].Unit {
+			case TrackAuto:
+				autos = append(autos, i)
+			case TrackFr:
+				flexible = true
+			}
+		}
+
+		extra := s.size - spanSize(sizes, s.start, s.span, gap)
+		if extra <= 0 || flexible || len(autos) == 0 {
+			continue
+		}
+		for _, i := range autos {
+			sizes[i] += extra / float32(len(autos))
+		}
+	}
+
+	free := innerSize - gap*float32(count-1)
+	sumFr := float32(0)
+	for i, t := range tracks {
+		if t.Unit == TrackFr {
+			sumFr += t.Value
+		} else {
+			free -= sizes[i]
+		}
+	}
+
+	if sumFr == 0 {
+		// No fr track: auto tracks stretch over the free space
+		var autos []int
+		for i, t := range tracks {
+			if t.Unit == TrackAuto {
+				autos = append(autos, i)
+			}
+		}
+		if free > 0 && len(autos) > 0 {
+			for _, i := range autos {
+				sizes[i] += free / float32(len(autos))
+			}
+		}
+		return sizes
+	}
+
+	// Find the size of 1fr: tracks whose content needs more than their
+	// share keep their base size and leave the rest to the others
+	inflexible := make([]bool, count)
+	for {
+		leftover, fr := free, float32(0)
+		for i, t := range tracks {
+			if t.Unit != TrackFr {
+				continue
+			}
+			if inflexible[i] {
+				leftover -= sizes[i]
+			} else {
+				fr += t.Value
+			}
+		}
+
+		if fr == 0 {
+			return sizes
+		}
+
+		// Like CSS, fractions summing below 1 leave space unused
+		unit := leftover / maxf(fr, 1)
+
+		violation := false
+		for i, t := range tracks {
+			if t.Unit == TrackFr && !inflexible[i] && unit*t.Value < sizes[i] {
+				inflexible[i] = true
+				violation = true
+			}
+		}
+
+		if !violation {
+			for i, t := range tracks {
+				if t.Unit == TrackFr && !inflexible[i] {
+					sizes[i] = unit * t.Value
+				}
+			}
+			return sizes
+		}
+	}
+}
+
+// trackPositions returns the start offset of each track
+func trackPositions(sizes []float32, start, gap float32) []float32 {
+	positions := make([]float32, len(sizes))
+	cursor := start
+	for i, size := range sizes {
+		positions[i] = cursor
+		cursor += size + gap
+	}
+	return positions
+}
+
+// spanSize is the size of span tracks from start, gaps included
+func spanSize(sizes []float32, start, span int, gap float32) float32 {
+	total := gap * float32(span-1)
+	for i := start; i < start+span; i++ {
+		total += sizes[i]
+	}
+	return total
+}
@@ -0,0 +1,169 @@
+package layout
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func gridItem(w, h Dimension, placement GridItem) *Node {
+	return &Node{Width: w, Height: h, GridItem: placement}
+}
+
+func gridContainer(grid Grid, padding float32, children ...*Node) *Node {
+	return &Node{
+		Mode:     ModeGrid,
+		Grid:     grid,
+		Padding:  Uniform(padding),
+		Children: children,
+	}
+}
+
+func TestGrid_Tracks(t *testing.T) {
+	icon := &Node{
+		Width:   Auto(),
+		Height:  Auto(),
+		Measure: func(w, h float32) (float32, float32) { return 50, 30 },
+	}
+
+	// Content box 380x180: columns 100 + 95 + fr + 50 with 3 gaps of 10,
+	// 1fr gets 105. Rows are 30 (the icon) and the remaining 140.
+	root := gridContainer(Grid{
+		Columns:   []Track{PxTrack(100), PctTrack(25), Fr(1), AutoTrack()},
+		Rows:      []Track{AutoTrack(), Fr(1)},
+		ColumnGap: 10,
+		RowGap:    10,
+	}, 10,
+		gridItem(Auto(), Auto(), GridItem{}),
+		gridItem(Auto(), Auto(), GridItem{}),
+		gridItem(Auto(), Auto(), GridItem{}),
+		icon,
+		gridItem(Px(40), Px(40), GridItem{}))
+	Compute(root, 400, 200)
+
+	assertBoxes(t, "tracks", root, []box{
+		{10, 10, 100, 30},
+		{120, 10, 95, 30},
+		{225, 10, 105, 30},
+		{340, 10, 50, 30},
+		{10, 50, 40, 40},
+	})
+}
+
+func TestGrid_Placement(t *testing.T) {
+	fill := func(h float32, placement GridItem) *Node {
+		return gridItem(Auto(), Px(h), placement)
+	}
+
+	// A is explicit, D is locked to row 2, the rest flows around them:
+	//
+	//	B B A
+	//	D C E
+	//	. . E
+	root := gridContainer(Grid{Columns: []Track{Fr(1), Fr(1), Fr(1)}}, 0,
+		fill(50, GridItem{Column: 3, Row: 1}),
+		fill(50, GridItem{ColumnSpan: 2}),
+		fill(40, GridItem{}),
+		fill(40, GridItem{Row: 2}),
+		gridItem(Auto(), Auto(), GridItem{RowSpan: 2}))
+	Compute(root, 300, 90)
+
+	assertBoxes(t, "placement", root, []box{
+		{200, 0, 100, 50},
+		{0, 0, 200, 50},
+		{100, 50, 100, 40},
+		{0, 50, 100, 40},
+		// Implicit third row is empty, E only covers row 2
+		{200, 50, 100, 40},
+	})
+
+	// Column locked item goes to the next row with that column free
+	root = gridContainer(Grid{Columns: []Track{Fr(1), Fr(1)}}, 0,
+		fill(10, GridItem{}),
+		fill(10, GridItem{}),
+		fill(10, GridItem{Column: 2}))
+	Compute(root, 100, 20)
+	assertBoxes(t, "column locked", root, []box{{0, 0, 50, 10}, {50, 0, 50, 10}, {50, 10, 50, 10}})
+}
+
+func TestGrid_LineLimit(t *testing.T) {
+	// Lines past the limit go to the last track instead of allocating
+	// billions of cells
+	root := gridContainer(Grid{Columns: []Track{PxTrack(10)}}, 0,
+		gridItem(Px(10), Px(10), GridItem{Column: 2000000000}),
+		gridItem(Px(10), Px(10), GridItem{Column: 2, ColumnSpan: 2000000000}))
+	areas, cols, _ := root.placeGridItems()
+	if cols != maxGridLines || areas[0].col != maxGridLines-1 || areas[0].colSpan != 1 {
+		t.Errorf("Expected column %d of %d, got %d of %d", maxGridLines-1, maxGridLines, areas[0].col, cols)
+	}
+	if areas[1].col != 1 || areas[1].colSpan != maxGridLines-1 {
+		t.Errorf("Span should end at the last line, got %d+%d", areas[1].col, areas[1].colSpan)
+	}
+
+	root = gridContainer(Grid{}, 0,
+		gridItem(Px(10), Px(10), GridItem{Row: 2000000000, RowSpan: 2000000000}))
+	Compute(root, 100, 100)
+	if _, _, rows := root.placeGridItems(); rows != maxGridLines {
+		t.Errorf("Expected %d rows, got %d", maxGridLines, rows)
+	}
+}
+
+func TestGrid_Alignment(t *testing.T) {
+	label := &Node{
+		Width:    Auto(),
+		Height:   Auto(),
+		Measure:  func(w, h float32) (float32, float32) { return 60, 30 },
+		GridItem: GridItem{Column: 2},
+	}
+
+	item := gridItem(Px(40), Px(20), GridItem{})
+	item.Margin.Bottom = 10
+
+	root := gridContainer(Grid{
+		Columns:      []Track{PxTrack(100), PxTrack(100)},
+		Rows:         []Track{PxTrack(100)},
+		JustifyItems: AlignCenter,
+		AlignItems:   AlignEnd,
+	}, 0, item, label)
+	Compute(root, 200, 100)
+
+	assertBoxes(t, "alignment", root, []box{{30, 70, 40, 20}, {120, 70, 60, 30}})
+}
+
+func TestGrid_ContentSizedTracks(t *testing.T) {
+	// The spanning item needs 200 from both auto tracks: col 1 has 50,
+	// the extra 150 is shared, then the free 100 stretches both
+	root := gridContainer(Grid{Columns: []Track{AutoTrack(), AutoTrack()}}, 0,
+		gridItem(Px(200), Px(10), GridItem{ColumnSpan: 2}),
+		gridItem(Px(50), Px(10), GridItem{}),
+		gridItem(Auto(), Px(10), GridItem{}))
+	Compute(root, 300, 20)
+
+	assertBoxes(t, "auto span", root, []box{{0, 0, 200, 10}, {0, 10, 50, 10}, {175, 10, 125, 10}})
+
+	// 1fr never gets smaller than its content
+	root = gridContainer(Grid{Columns: []Track{Fr(1), Fr(1)}}, 0,
+		gridItem(Px(150), Px(10), GridItem{}),
+		gridItem(Auto(), Px(10), GridItem{}))
+	Compute(root, 200, 10)
+
+	assertBoxes(t, "fr minimum", root, []box{{0, 0, 150, 10}, {150, 0, 50, 10}})
+}
+
+func TestTrack_JSON(t *testing.T) {
+	var tracks []Track
+	if err := json.Unmarshal([]byte(`["100px", 50, "25%", "1fr", "auto", "0.5fr"]`), &tracks); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Track{PxTrack(100), PxTrack(50), PctTrack(25), Fr(1), AutoTrack(), Fr(0.5)}
+	for i, track := range tracks {
+		if track != expected[i] {
+			t.Errorf("Track %d: expected %+v, got %+v", i, expected[i], track)
+		}
+	}
+
+	data, _ := json.Marshal(tracks)
+	if string(data) != `[100.00,50.00,"25.00%","1.00fr","auto","0.50fr"]` {
+		t.Errorf("Wrong JSON, got %s", data)
+	}
+}
@@ -0,0 +1,77 @@
+package layout
+
+// contentSize is the border box size n hugs its in flow children with,
+// given the size available to n. It is the intrinsic size of containers
+// with an auto Width/Height, nested auto containers measure their own
+// children the same way so sizes propagate up the tree.
+func (n *Node) contentSize(availableWidth, availableHeight float32) (float32, float32) {
+	in := n.inset()
+	innerW := maxf(0, availableWidth-in.Horizontal())
+	innerH := maxf(0, availableHeight-in.Vertical())
+
+	var w, h float32
+	switch n.Mode {
+	case ModeFlex:
+		w, h = n.flexContentSize(innerW, innerH)
+	case ModeGrid:
+		_, cols, rows := n.gridTracks(innerW, innerH, 0, 0)
+		w = spanSize(cols, 0, len(cols), n.Grid.ColumnGap)
+		h = spanSize(rows, 0, len(rows), n.Grid.RowGap)
+	default:
+		// Children are placed from the border box, only the far
+		// side padding and border are added
+		for _, child := range n.Children {
+			if !child.Position.inFlow() {
+				continue
+			}
+			cw, ch := child.resolveBox(availableWidth, availableHeight, availableWidth, availableHeight, false, false)
+			w = maxf(w, child.Left.Resolve(availableWidth)+cw)
+			h = maxf(h, child.Top.Resolve(availableHeight)+ch)
+		}
+		return w + in.Right, h + in.Bottom
+	}
+
+	return w + in.Horizontal(), h + in.Vertical()
+}
+
+// flexContentSize lays items out at their hypothetical sizes, lines
+// break at the available main size when the container wraps
+func (n *Node) flexContentSize(innerW, innerH float32) (float32, float32) {
+	row := n.Flex.Direction.isRow()
+	innerMain, innerCross := innerW, innerH
+	mainGap, crossGap := n.Flex.ColumnGap, n.Flex.RowGap
+	if !row {
+		innerMain, innerCross = innerH, innerW
+		mainGap, crossGap = crossGap, mainGap
+	}
+
+	items := n.flexItems(innerW, innerH)
+	lines := breakLines(items, innerMain, mainGap, n.Flex.Wrap != WrapNone)
+
+	var main, cross float32
+	count := 0
+	for _, line := range lines {
+		if len(line.items) == 0 {
+			continue
+		}
+
+		lineMain, lineCross := mainGap*float32(len(line.items)-1), float32(0)
+		for _, it := range line.items {
+			it.hypotheticalCross(row, innerCross)
+			lineMain += it.outerMain()
+			lineCross = maxf(lineCross, it.cross+it.crossMargin[0]+it.crossMargin[1])
+		}
+
+		main = maxf(main, lineMain)
+		cross += lineCross
+		count++
+	}
+	if count > 1 {
+		cross += crossGap * float32(count-1)
+	}
+
+	if row {
+		return main, cross
+	}
+	return cross, main
+}
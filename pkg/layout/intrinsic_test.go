@@ -0,0 +1,86 @@
+package layout
+
+import "testing"
+
+// hug returns an auto sized container
+func hug(mode Mode, flex Flex, padding float32, children ...*Node) *Node {
+	return &Node{
+		Mode:     mode,
+		Flex:     flex,
+		Width:    Auto(),
+		Height:   Auto(),
+		Padding:  Uniform(padding),
+		Children: children,
+	}
+}
+
+func TestIntrinsic_FlexHug(t *testing.T) {
+	row := hug(ModeFlex, Flex{ColumnGap: 5}, 10, fixed(50, 20), fixed(30, 40))
+	column := hug(ModeFlex, Flex{Direction: DirectionColumn, RowGap: 4}, 0, fixed(50, 20), fixed(30, 40))
+	root := &Node{Children: []*Node{row, column}}
+	Compute(root, 500, 500)
+
+	assertBoxes(t, "hug", root, []box{{0, 0, 105, 60}, {0, 0, 50, 64}})
+	// Children fit exactly, nothing to stretch on the main axis
+	assertBoxes(t, "hug row", row, []box{{10, 10, 50, 20}, {65, 10, 30, 40}})
+}
+
+func TestIntrinsic_Nested(t *testing.T) {
+	label := &Node{Width: Auto(), Height: Auto(), Measure: func(w, h float32) (float32, float32) {
+		return 40, 12
+	}}
+	icons := hug(ModeFlex, Flex{ColumnGap: 2}, 0, fixed(10, 10), fixed(10, 10), fixed(10, 10))
+	card := hug(ModeFlex, Flex{Direction: DirectionColumn, AlignItem: AlignStart}, 5, icons, label)
+	root := &Node{Children: []*Node{card}}
+	Compute(root, 500, 500)
+
+	// Widest child plus padding, stacked heights plus padding
+	assertBoxes(t, "card", root, []box{{0, 0, 50, 32}})
+	assertBoxes(t, "card children", card, []box{{5, 5, 34, 10}, {5, 15, 40, 12}})
+}
+
+func TestIntrinsic_FlexBasis(t *testing.T) {
+	// The auto item starts from its content, the other grows into the rest
+	auto := hug(ModeNone, Flex{}, 0, fixed(60, 10))
+	root := container(Flex{AlignItem: AlignStart}, 0, auto, grow(1, 10))
+	Compute(root, 300, 100)
+
+	assertBoxes(t, "basis", root, []box{{0, 0, 60, 10}, {60, 0, 240, 10}})
+}
+
+func TestIntrinsic_Wrap(t *testing.T) {
+	var items []*Node
+	for range 6 {
+		items = append(items, fixed(30, 10))
+	}
+	wrapping := hug(ModeFlex, Flex{Wrap: WrapLine, RowGap: 4}, 0, items...)
+	wrapping.Width = Px(100)
+	root := &Node{Children: []*Node{wrapping}}
+	Compute(root, 500, 500)
+
+	// Three items per line, two lines
+	assertBoxes(t, "wrap", root, []box{{0, 0, 100, 24}})
+}
+
+func TestIntrinsic_Grid(t *testing.T) {
+	grid := hug(ModeGrid, Flex{}, 0, fixed(40, 10), fixed(20, 30))
+	grid.Grid = Grid{Columns: []Track{Fr(1), AutoTrack()}, ColumnGap: 10}
+	root := &Node{Children: []*Node{grid}}
+	Compute(root, 500, 500)
+
+	// fr tracks only fit their content in a hugging grid
+	assertBoxes(t, "grid", root, []box{{0, 0, 70, 30}})
+}
+
+func TestIntrinsic_Free(t *testing.T) {
+	group := hug(ModeNone, Flex{}, 0,
+		&Node{Width: Px(20), Height: Px(20), Left: Px(10), Top: Px(5)},
+		&Node{Width: Px(50), Height: Px(10), Left: Px(0), Top: Px(30)},
+		// Out of flow children don't count
+		&Node{Position: PositionAbsolute, Width: Px(500), Height: Px(500)},
+	)
+	root := &Node{Children: []*Node{group}}
+	Compute(root, 500, 500)
+
+	assertBoxes(t, "free", root, []box{{0, 0, 50, 40}})
+}
@@ -1 +1,171 @@
 package layout
+
+import "math"
+
+// MeasureFunc returns the intrinsic size of a leaf (text, image...)
+// given the space available to it
+type MeasureFunc func(availableWidth, availableHeight float32) (float32, float32)
+
+// Edges holds a value per side, in pixel
+type Edges struct {
+	Top, Right, Bottom, Left float32
+}
+
+func Uniform(v float32) Edges {
+	return Edges{Top: v, Right: v, Bottom: v, Left: v}
+}
+
+func (e Edges) Horizontal() float32 {
+	return e.Left + e.Right
+}
+
+func (e Edges) Vertical() float32 {
+	return e.Top + e.Bottom
+}
+
+// Node is one box of the layout tree, it mirrors a scene node with
+// only what the layout pass needs. Compute fills X, Y, W, H.
+type Node struct {
+	// How children are placed
+	Mode Mode
+	// Container properties when Mode is ModeFlex,
+	// Grow/Shrink are read when the parent is a flex container
+	Flex Flex
+	// Container properties when Mode is ModeGrid
+	Grid Grid
+	// Placement inside a ModeGrid parent
+	GridItem GridItem
+
+	Width, Height Dimension
+	// Bounds of the border box. An auto or zero max means no maximum,
+	// like CSS "none"; the min wins when both conflict.
+	MinWidth, MaxWidth   Dimension
+	MinHeight, MaxHeight Dimension
+	// Width / height, 0 if none. Sizes the auto axis from the other one.
+	AspectRatio float32
+	// How Left/Top/Right/Bottom apply, see Position
+	Position                 Position
+	Left, Top, Right, Bottom Dimension
+
+	// Children scroll inside the padding box, ScrollX/ScrollY is
+	// the current offset. Sticky and fixed nodes follow it.
+	Scroll           bool
+	ScrollX, ScrollY float32
+
+	Margin  Edges
+	Padding Edges
+	Border  Edges
+
+	// Optional, intrinsic size of leaves with auto Width/Height.
+	// Containers without one hug their children.
+	Measure MeasureFunc
+
+	Children []*Node
+
+	// Computed border box, X/Y relative to the parent's border box
+	X, Y, W, H float32
+}
+
+func (n *Node) AppendChild(child *Node) {
+	n.Children = append(n.Children, child)
+}
+
+// inset is the space between the border box and the content box
+func (n *Node) inset() Edges {
+	return Edges{
+		Top:    n.Border.Top + n.Padding.Top,
+		Right:  n.Border.Right + n.Padding.Right,
+		Bottom: n.Border.Bottom + n.Padding.Bottom,
+		Left:   n.Border.Left + n.Padding.Left,
+	}
+}
+
+// innerSize is the content box size children are laid out in
+func (n *Node) innerSize() (float32, float32) {
+	in := n.inset()
+	return maxf(0, n.W-in.Horizontal()), maxf(0, n.H-in.Vertical())
+}
+
+// measure returns the intrinsic size: Measure for leaves, the size
+// hugging the children for containers, 0 for empty nodes
+func (n *Node) measure(availableWidth, availableHeight float32) (float32, float32) {
+	if n.Measure != nil {
+		return n.Measure(availableWidth, availableHeight)
+	}
+	if len(n.Children) > 0 {
+		return n.contentSize(availableWidth, availableHeight)
+	}
+	return 0, 0
+}
+
+// widthBounds resolves MinWidth/MaxWidth against the parent width
+func (n *Node) widthBounds(parentW float32) (float32, float32) {
+	return resolveMin(n.MinWidth, parentW), resolveMax(n.MaxWidth, parentW)
+}
+
+func (n *Node) heightBounds(parentH float32) (float32, float32) {
+	return resolveMin(n.MinHeight, parentH), resolveMax(n.MaxHeight, parentH)
+}
+
+func resolveMin(d Dimension, parentSize float32) float32 {
+	return maxf(0, d.Resolve(parentSize))
+}
+
+func resolveMax(d Dimension, parentSize float32) float32 {
+	if d.Unit == UnitAuto || d == (Dimension{}) {
+		return float32(math.Inf(1))
+	}
+	return d.Resolve(parentSize)
+}
+
+// resolveBox sizes n in a box of availW x availH. Fixed sizes resolve
+// against refW/refH, then the aspect ratio derives an auto axis from the
+// other, then auto axes stretch to the available size or fall back to
+// the measured size. Both axes are clamped by their min/max.
+func (n *Node) resolveBox(refW, refH, availW, availH float32, stretchW, stretchH bool) (float32, float32) {
+	minW, maxW := n.widthBounds(refW)
+	minH, maxH := n.heightBounds(refH)
+
+	wAuto, hAuto := n.Width.Unit == UnitAuto, n.Height.Unit == UnitAuto
+	w, h := n.Width.Resolve(refW), n.Height.Resolve(refH)
+
+	if r := n.AspectRatio; r > 0 && wAuto != hAuto {
+		if wAuto {
+			h = clamp(h, minH, maxH)
+			return clamp(h*r, minW, maxW), h
+		}
+		w = clamp(w, minW, maxW)
+		return w, clamp(w/r, minH, maxH)
+	}
+
+	if wAuto || hAuto {
+		// A fixed axis is all the space the content gets
+		if !wAuto {
+			availW = clamp(w, minW, maxW)
+		}
+		if !hAuto {
+			availH = clamp(h, minH, maxH)
+		}
+		mw, mh := n.measure(availW, availH)
+		if wAuto {
+			w = mw
+			if stretchW {
+				w = availW
+			}
+		}
+		if hAuto {
+			h = mh
+			if stretchH {
+				h = availH
+			}
+		}
+
+		// Both auto: keep the width, the height follows
+		if r := n.AspectRatio; r > 0 && wAuto && hAuto {
+			w = clamp(w, minW, maxW)
+			return w, clamp(w/r, minH, maxH)
+		}
+	}
+
+	return clamp(w, minW, maxW), clamp(h, minH, maxH)
+}
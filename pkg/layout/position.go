@@ -0,0 +1,154 @@
+package layout
+
+// Position chooses how the insets (Left, Top, Right, Bottom) move a node
+type Position int
+
+const (
+	// In flow, the insets shift the node from where the parent placed it.
+	// Inside a ModeNone parent the insets are the position itself.
+	PositionRelative Position = iota
+	// Out of flow, placed by its insets inside the padding box of the parent
+	PositionAbsolute
+	// In flow, but kept inside the scrollport of the nearest scroll
+	// container by its insets, without leaving its parent
+	PositionSticky
+	// Out of flow, placed by its insets inside the scrollport of the
+	// nearest scroll container and does not move when it scrolls
+	PositionFixed
+)
+
+// inFlow reports whether the parent's flex or grid pass places the node
+func (p Position) inFlow() bool {
+	return p == PositionRelative || p == PositionSticky
+}
+
+// scrollContext is the nearest scroll container while descending the
+// tree, x/y is the offset of the current node's border box inside it
+type scrollContext struct {
+	node *Node
+	x, y float32
+}
+
+// enter returns the context of child, a scroll container starts its own
+func (ctx scrollContext) enter(child *Node) scrollContext {
+	if child.Scroll {
+		return scrollContext{node: child}
+	}
+	return scrollContext{node: ctx.node, x: ctx.x + child.X, y: ctx.y + child.Y}
+}
+
+// viewport is the scrollport of the scroll container, its padding box,
+// in its own coordinates without the scroll offset
+func (ctx scrollContext) viewport() (x, y, w, h float32) {
+	s := ctx.node
+	return s.Border.Left, s.Border.Top,
+		maxf(0, s.W-s.Border.Horizontal()), maxf(0, s.H-s.Border.Vertical())
+}
+
+// layoutPositioned runs after the parent's own pass: out of flow
+// children are sized and placed, in flow children get their offsets
+func (n *Node) layoutPositioned(ctx scrollContext) {
+	for _, child := range n.Children {
+		switch child.Position {
+		case PositionAbsolute:
+			child.placeInset(n.Border.Left, n.Border.Top,
+				maxf(0, n.W-n.Border.Horizontal()), maxf(0, n.H-n.Border.Vertical()))
+		case PositionFixed:
+			x, y, w, h := ctx.viewport()
+			child.placeInset(x, y, w, h)
+			// Cancel the scroll offset and the path down from the container
+			child.X += ctx.node.ScrollX - ctx.x
+			child.Y += ctx.node.ScrollY - ctx.y
+		case PositionRelative:
+			if n.Mode != ModeNone {
+				innerW, innerH := n.innerSize()
+				child.X += relativeOffset(child.Left, child.Right, innerW)
+				child.Y += relativeOffset(child.Top, child.Bottom, innerH)
+			}
+		case PositionSticky:
+			n.stick(child, ctx)
+		}
+	}
+}
+
+// placeFree places a child of a ModeNone parent by its Left/Top,
+// or from the far edge by its Right/Bottom when Left/Top is auto
+func (n *Node) placeFree(child *Node) {
+	child.W, child.H = child.resolveBox(n.W, n.H, n.W, n.H, false, false)
+	child.X = insetPosition(child.Left, child.Right, 0, n.W, child.W, 0, 0)
+	child.Y = insetPosition(child.Top, child.Bottom, 0, n.H, child.H, 0, 0)
+}
+
+// placeInset sizes and places an out of flow node inside the box
+// (x, y, w, h). An auto size with both insets set fills the space
+// between them, a node without insets sits at the box start.
+func (n *Node) placeInset(x, y, w, h float32) {
+	availW, availH := w, h
+	stretchW := n.Width.Unit == UnitAuto && n.Left.Unit != UnitAuto && n.Right.Unit != UnitAuto
+	stretchH := n.Height.Unit == UnitAuto && n.Top.Unit != UnitAuto && n.Bottom.Unit != UnitAuto
+	if stretchW {
+		availW = maxf(0, w-n.Left.Resolve(w)-n.Right.Resolve(w)-n.Margin.Horizontal())
+	}
+	if stretchH {
+		availH = maxf(0, h-n.Top.Resolve(h)-n.Bottom.Resolve(h)-n.Margin.Vertical())
+	}
+
+	n.W, n.H = n.resolveBox(w, h, availW, availH, stretchW, stretchH)
+	n.X = insetPosition(n.Left, n.Right, x, w, n.W, n.Margin.Left, n.Margin.Right)
+	n.Y = insetPosition(n.Top, n.Bottom, y, h, n.H, n.Margin.Top, n.Margin.Bottom)
+}
+
+// insetPosition resolves the start of a box of size inside [start,
+// start+length] from its start inset, else from its end inset
+func insetPosition(startInset, endInset Dimension, start, length, size, marginStart, marginEnd float32) float32 {
+	switch {
+	case startInset.Unit != UnitAuto:
+		return start + startInset.Resolve(length) + marginStart
+	case endInset.Unit != UnitAuto:
+		return start + length - endInset.Resolve(length) - marginEnd - size
+	}
+	return start + marginStart
+}
+
+// relativeOffset is the shift of a relative node, start wins over end
+func relativeOffset(startInset, endInset Dimension, length float32) float32 {
+	switch {
+	case startInset.Unit != UnitAuto:
+		return startInset.Resolve(length)
+	case endInset.Unit != UnitAuto:
+		return -endInset.Resolve(length)
+	}
+	return 0
+}
+
+// stick moves a sticky child so it stays inside the scrollport by its
+// insets. It never leaves the content box of n, unless n is the scroll
+// container itself whose content is the whole scrollable area.
+func (n *Node) stick(child *Node, ctx scrollContext) {
+	vx, vy, vw, vh := ctx.viewport()
+	flowX, flowY := child.X, child.Y
+
+	child.X = stickAxis(child.X, child.W, child.Left, child.Right, ctx.node.ScrollX+vx-ctx.x, vw)
+	child.Y = stickAxis(child.Y, child.H, child.Top, child.Bottom, ctx.node.ScrollY+vy-ctx.y, vh)
+
+	if ctx.node != n {
+		// The parent never pushes it back past its flow position
+		in := n.inset()
+		child.X = clamp(child.X, minf(flowX, in.Left+child.Margin.Left),
+			maxf(flowX, n.W-in.Right-child.Margin.Right-child.W))
+		child.Y = clamp(child.Y, minf(flowY, in.Top+child.Margin.Top),
+			maxf(flowY, n.H-in.Bottom-child.Margin.Bottom-child.H))
+	}
+}
+
+// stickAxis clamps the flow position pos of a box of size by the
+// visible range [view, view+length] shrunk by the insets
+func stickAxis(pos, size float32, startInset, endInset Dimension, view, length float32) float32 {
+	if startInset.Unit != UnitAuto {
+		pos = maxf(pos, view+startInset.Resolve(length))
+	}
+	if endInset.Unit != UnitAuto {
+		pos = minf(pos, view+length-endInset.Resolve(length)-size)
+	}
+	return pos
+}
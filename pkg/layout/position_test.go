@@ -0,0 +1,113 @@
+package layout
+
+import "testing"
+
+func TestPosition_Absolute(t *testing.T) {
+	root := container(Flex{}, 10,
+		fixed(50, 20),
+		// Stretched between left/right, pinned to the bottom
+		&Node{Position: PositionAbsolute, Width: Auto(), Height: Px(10),
+			Left: Px(5), Right: Px(5), Top: Auto(), Bottom: Px(0)},
+		fixed(50, 20),
+		// Percent insets resolve against the padding box
+		&Node{Position: PositionAbsolute, Width: Px(40), Height: Px(40),
+			Left: Auto(), Right: Pct(10), Top: Pct(50), Bottom: Auto()},
+	)
+	Compute(root, 300, 100)
+
+	// Absolute items take no room in the flex line
+	assertBoxes(t, "absolute", root, []box{
+		{10, 10, 50, 20},
+		{5, 90, 290, 10},
+		{60, 10, 50, 20},
+		{230, 50, 40, 40},
+	})
+}
+
+func TestPosition_AbsoluteInGrid(t *testing.T) {
+	root := gridContainer(Grid{Columns: []Track{Fr(1), Fr(1)}}, 0,
+		fixed(10, 10),
+		&Node{Position: PositionAbsolute, Width: Px(10), Height: Px(10),
+			Left: Auto(), Right: Px(0), Top: Auto(), Bottom: Auto()},
+		fixed(10, 10),
+	)
+	Compute(root, 200, 100)
+
+	// The second in flow item takes the second column
+	assertBoxes(t, "absolute grid", root, []box{{0, 0, 10, 10}, {190, 0, 10, 10}, {100, 0, 10, 10}})
+}
+
+func TestPosition_Relative(t *testing.T) {
+	shifted := func(left, top, right, bottom Dimension) *Node {
+		n := fixed(50, 20)
+		n.Left, n.Top, n.Right, n.Bottom = left, top, right, bottom
+		return n
+	}
+
+	root := container(Flex{AlignItem: AlignStart}, 0,
+		shifted(Px(5), Auto(), Auto(), Px(4)),
+		// 10% of the 200px content box
+		shifted(Auto(), Auto(), Pct(10), Auto()),
+		// Left wins over right
+		shifted(Px(1), Px(2), Px(50), Px(50)),
+	)
+	Compute(root, 200, 100)
+
+	assertBoxes(t, "relative", root, []box{{5, -4, 50, 20}, {30, 0, 50, 20}, {101, 2, 50, 20}})
+}
+
+func TestPosition_FreeInsets(t *testing.T) {
+	root := &Node{Children: []*Node{
+		{Width: Px(20), Height: Px(10), Left: Auto(), Top: Auto(), Right: Px(5), Bottom: Px(5)},
+		{Width: Px(20), Height: Px(10), Left: Px(5), Top: Pct(50), Right: Px(100), Bottom: Auto()},
+	}}
+	Compute(root, 100, 100)
+
+	assertBoxes(t, "free insets", root, []box{{75, 85, 20, 10}, {5, 50, 20, 10}})
+}
+
+func TestPosition_StickyAndFixed(t *testing.T) {
+	// A 100x100 scroll container with a 200px tall section at y 50,
+	// the section has a sticky header and a fixed badge
+	build := func(scrollY float32) *Node {
+		header := &Node{Position: PositionSticky, Width: Px(100), Height: Px(20),
+			Left: Auto(), Top: Px(0), Right: Auto(), Bottom: Auto()}
+		badge := &Node{Position: PositionFixed, Width: Px(20), Height: Px(20),
+			Left: Auto(), Top: Auto(), Right: Px(10), Bottom: Px(10)}
+		section := &Node{Width: Px(100), Height: Px(200), Left: Px(0), Top: Px(50),
+			Children: []*Node{header, badge}}
+
+		root := &Node{Scroll: true, ScrollY: scrollY, Children: []*Node{section}}
+		Compute(root, 100, 100)
+		return section
+	}
+
+	cases := []struct {
+		scrollY float32
+		header  float32
+		badge   float32
+	}{
+		// Not scrolled past the section yet, the header stays in flow
+		{0, 0, 20},
+		// Stuck to the top of the scrollport
+		{100, 50, 120},
+		// Stopped at the bottom of the section
+		{300, 180, 320},
+	}
+
+	for _, c := range cases {
+		section := build(c.scrollY)
+		header, badge := section.Children[0], section.Children[1]
+
+		if header.Y != c.header {
+			t.Errorf("scroll %v: header y should be %v, got %v", c.scrollY, c.header, header.Y)
+		}
+		if badge.X != 70 || badge.Y != c.badge {
+			t.Errorf("scroll %v: badge should be at (70, %v), got (%v, %v)", c.scrollY, c.badge, badge.X, badge.Y)
+		}
+		// On screen the badge never moves
+		if screen := section.Y + badge.Y - c.scrollY; screen != 70 {
+			t.Errorf("scroll %v: badge should stay at 70 in the scrollport, got %v", c.scrollY, screen)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package scene
+
+// IDAllocator hands out node IDs unique within one scene graph.
+// IDs are sequential so the same edits always produce the same IDs,
+// 0 is never allocated and means "no node".
+type IDAllocator struct {
+	last uint32
+}
+
+func (a *IDAllocator) Next() uint32 {
+	a.last++
+	return a.last
+}
+
+// Observe marks id as used, so IDs coming from a loaded
+// document are never handed out again
+func (a *IDAllocator) Observe(id uint32) {
+	if id > a.last {
+		a.last = id
+	}
+}
+
+func (a *IDAllocator) Reset() {
+	a.last = 0
+}
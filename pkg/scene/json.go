@@ -0,0 +1,341 @@
+package scene
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"engo/pkg/layout"
+	"engo/pkg/style"
+)
+
+// DocumentVersion is the version of JSON document schema below.
+// Bump it whenever the schema changes in a non backward-compatible way.
+const DocumentVersion = 1
+
+// A JSON document holds exactly one page:
+//
+//	{
+//	  "version": 1,
+//	  "root": <node>
+//	}
+//
+// Each <node> has the shape:
+//
+//	{
+//	  "id": 12,                  // unique inside the document, 0 is reserved
+//	  "type": "FRAME",           // NodeType name, see nodeTypeNames
+//	  "element": "DIV",          // optional HTMLElementType name, default "DIV"
+//	  "hidden": true,            // optional, not drawn nor hit
+//	  "locked": true,            // optional, drawn but not hit
+//	  "style": { ... },          // optional, see below
+//	  "props": { ... },          // optional, shape depends on "type"
+//	  "children": [ <node> ]     // optional, in paint order
+//	}
+//
+// "style" keys, missing ones default to style.NewStyle():
+//   - width, height, minWidth, maxWidth, minHeight, maxHeight, top, right,
+//     bottom and left are layout.Dimension values (100, "100px", "50%" or
+//     "auto"). They are kept as written, the layout pass resolves them.
+//     A max of "none", "auto" or 0 means no maximum.
+//   - margin, padding and borderWidth are style.Edges, either one
+//     Dimension for every side or {top, right, bottom, left}
+//   - aspectRatio is width / height, 0 if none
+//   - position and overflow are style enum values, opacity default to 1
+//   - rotation is in degrees, clockwise around the center of the box
+//   - layout is a layout.Mode and flex holds the layout.Flex properties
+//     (direction, justifyContent, alignItems, grow, shrink, wrap,
+//     alignContent, rowGap, columnGap)
+//   - grid holds the layout.Grid properties (columns and rows as lists of
+//     tracks like 100, "25%", "1fr" or "auto", rowGap, columnGap,
+//     justifyItems, alignItems) and gridItem the placement inside a grid
+//     parent (column, row, columnSpan, rowSpan, lines from 1)
+//
+// "props" is decoded into RectProps for FRAME, POLYGON, LINE, VECTOR,
+// COMPONENT, INSTANCE and STICKY, TextProps for TEXT, ImageProps for IMAGE
+// and ignored for the rest. "shape" of RectProps is a Shape value and
+// "path" the [x, y, ...] points of ShapePath.
+//
+// The root must be the one and only PAGE node of the document.
+//
+// MarshalDocument writes the same schema with a fixed key order. Lengths,
+// percentages and grid tracks keep their unit but are rounded to 2
+// decimals, so the first export of a fractional value like a drag
+// position is lossy. From then on export -> UnmarshalDocument -> export
+// is byte-identical.
+
+var (
+	ErrUnsupportedVersion = errors.New("scene: unsupported document version")
+	ErrInvalidRoot        = errors.New("scene: document root must be a page node")
+	ErrDuplicateID        = errors.New("scene: duplicate node id")
+)
+
+type documentJSON struct {
+	Version int       `json:"version"`
+	Root    *nodeJSON `json:"root"`
+}
+
+type nodeJSON struct {
+	ID       uint32          `json:"id"`
+	Type     NodeType        `json:"type"`
+	Element  HTMLElementType `json:"element,omitempty"`
+	Hidden   bool            `json:"hidden,omitempty"`
+	Locked   bool            `json:"locked,omitempty"`
+	Style    *styleJSON      `json:"style,omitempty"`
+	Props    json.RawMessage `json:"props,omitempty"`
+	Children []*nodeJSON     `json:"children,omitempty"`
+}
+
+// styleJSON mirrors style.Style field by field, with JSON keys
+type styleJSON struct {
+	Width       layout.Dimension  `json:"width"`
+	Height      layout.Dimension  `json:"height"`
+	MinWidth    layout.Dimension  `json:"minWidth"`
+	MaxWidth    layout.Dimension  `json:"maxWidth"`
+	MinHeight   layout.Dimension  `json:"minHeight"`
+	MaxHeight   layout.Dimension  `json:"maxHeight"`
+	AspectRatio float32           `json:"aspectRatio"`
+	Top         layout.Dimension  `json:"top"`
+	Right       layout.Dimension  `json:"right"`
+	Bottom      layout.Dimension  `json:"bottom"`
+	Left        layout.Dimension  `json:"left"`
+	Margin      style.Edges       `json:"margin"`
+	Padding     style.Edges       `json:"padding"`
+	BorderWidth style.Edges       `json:"borderWidth"`
+	Position    style.Position    `json:"position"`
+	Overflow    style.Overflow    `json:"overflow"`
+	Opacity     float32           `json:"opacity"`
+	Rotation    float32           `json:"rotation"`
+	Layout      layout.Mode       `json:"layout"`
+	Flex        layout.Flex       `json:"flex"`
+	Grid        layout.Grid       `json:"grid"`
+	GridItem    layout.GridItem   `json:"gridItem"`
+	Constraints style.Constraints `json:"constraints"`
+}
+
+func (s *styleJSON) UnmarshalJSON(data []byte) error {
+	// alias drops the method set, so json doesn't recurse into here
+	type alias styleJSON
+
+	// Missing keys keep the defaults of a new node
+	decoded := alias(*newStyleJSON(style.NewStyle()))
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*s = styleJSON(decoded)
+	return nil
+}
+
+// UnmarshalDocument parses a JSON document and rebuilds its scene graph,
+// returning the page node. Every node is flagged dirty so the first
+// reconcile walks the whole tree.
+func UnmarshalDocument(data []byte) (*Node, error) {
+	var doc documentJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Version < 1 || doc.Version > DocumentVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, doc.Version)
+	}
+
+	if doc.Root == nil || doc.Root.Type != Page {
+		return nil, ErrInvalidRoot
+	}
+
+	seen := make(map[uint32]bool)
+	return doc.Root.toNode(nil, seen)
+}
+
+func (j *nodeJSON) toNode(parent *Node, seen map[uint32]bool) (*Node, error) {
+	if j.ID == 0 {
+		return nil, fmt.Errorf("scene: node of type %s has no id", j.Type)
+	}
+
+	if seen[j.ID] {
+		return nil, fmt.Errorf("%w: %d", ErrDuplicateID, j.ID)
+	}
+	seen[j.ID] = true
+
+	if parent != nil && j.Type == Page {
+		return nil, ErrInvalidRoot
+	}
+
+	node := NewNode(j.Type, nil)
+	node.ID = j.ID
+	node.HTMLElementType = j.Element
+	node.Hidden = j.Hidden
+	node.Locked = j.Locked
+	node.Style = j.Style.toStyle()
+	node.Props = NewProps(j.Type)
+	node.Flags = FlagContentDirty | FlagLayoutDirty | FlagSubtreeDirty
+
+	if node.Props != nil && len(j.Props) > 0 {
+		if err := json.Unmarshal(j.Props, node.Props); err != nil {
+			return nil, fmt.Errorf("scene: node %d props: %w", j.ID, err)
+		}
+	}
+
+	for _, childJSON := range j.Children {
+		child, err := childJSON.toNode(node, seen)
+		if err != nil {
+			return nil, err
+		}
+
+		node.AppendChild(child)
+	}
+
+	return node, nil
+}
+
+func (s *styleJSON) toStyle() *style.Style {
+	if s == nil {
+		return style.NewStyle()
+	}
+
+	st := style.Style(*s)
+	st.Grid = st.Grid.Clone()
+	return &st
+}
+
+// MarshalDocument serializes the scene graph under root into
+// a JSON document that UnmarshalDocument can load back
+func MarshalDocument(root *Node) ([]byte, error) {
+	if root == nil || root.Type != Page {
+		return nil, ErrInvalidRoot
+	}
+
+	rootJSON, err := newNodeJSON(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(documentJSON{
+		Version: DocumentVersion,
+		Root:    rootJSON,
+	})
+}
+
+func newNodeJSON(node *Node) (*nodeJSON, error) {
+	j := &nodeJSON{
+		ID:      node.ID,
+		Type:    node.Type,
+		Element: node.HTMLElementType,
+		Hidden:  node.Hidden,
+		Locked:  node.Locked,
+		Style:   newStyleJSON(node.Style),
+	}
+
+	// Always written for types that carry props, so a node without props
+	// exports the same as it reloads (with empty props)
+	if defaults := NewProps(node.Type); defaults != nil {
+		if node.Props != nil {
+			defaults = node.Props
+		}
+
+		props, err := json.Marshal(defaults)
+		if err != nil {
+			return nil, fmt.Errorf("scene: node %d props: %w", node.ID, err)
+		}
+		j.Props = props
+	}
+
+	for _, child := range node.Children {
+		childJSON, err := newNodeJSON(child)
+		if err != nil {
+			return nil, err
+		}
+		j.Children = append(j.Children, childJSON)
+	}
+
+	return j, nil
+}
+
+func newStyleJSON(s *style.Style) *styleJSON {
+	// Same default as a node loaded without "style"
+	if s == nil {
+		s = style.NewStyle()
+	}
+
+	j := styleJSON(*s)
+	return &j
+}
+
+var nodeTypeNames = [...]string{
+	Page:      "PAGE",
+	Frame:     "FRAME",
+	Section:   "SECTION",
+	Vector:    "VECTOR",
+	Group:     "GROUP",
+	Polygon:   "POLYGON",
+	Line:      "LINE",
+	Text:      "TEXT",
+	Image:     "IMAGE",
+	Component: "COMPONENT",
+	Instance:  "INSTANCE",
+	Connector: "CONNECTOR",
+	Sticky:    "STICKY",
+}
+
+func (t NodeType) IsValid() bool {
+	return t >= 0 && int(t) < len(nodeTypeNames)
+}
+
+func (t NodeType) String() string {
+	if !t.IsValid() {
+		return fmt.Sprintf("NodeType(%d)", int(t))
+	}
+	return nodeTypeNames[t]
+}
+
+func (t NodeType) MarshalText() ([]byte, error) {
+	if !t.IsValid() {
+		return nil, fmt.Errorf("scene: unknown node type %d", int(t))
+	}
+	return []byte(nodeTypeNames[t]), nil
+}
+
+func (t *NodeType) UnmarshalText(text []byte) error {
+	for i, name := range nodeTypeNames {
+		if name == string(text) {
+			*t = NodeType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("scene: unknown node type %q", text)
+}
+
+var htmlElementTypeNames = [...]string{
+	Div:      "DIV",
+	Button:   "BUTTON",
+	Anchor:   "ANCHOR",
+	Nav:      "NAV",
+	List:     "LIST",
+	ListItem: "LIST_ITEM",
+	Checkbox: "CHECKBOX",
+	Radio:    "RADIO",
+	Toggle:   "TOGGLE",
+	Input:    "INPUT",
+}
+
+func (t HTMLElementType) IsValid() bool {
+	return t >= 0 && int(t) < len(htmlElementTypeNames)
+}
+
+func (t HTMLElementType) MarshalText() ([]byte, error) {
+	if !t.IsValid() {
+		return nil, fmt.Errorf("scene: unknown element type %d", int(t))
+	}
+	return []byte(htmlElementTypeNames[t]), nil
+}
+
+func (t *HTMLElementType) UnmarshalText(text []byte) error {
+	for i, name := range htmlElementTypeNames {
+		if name == string(text) {
+			*t = HTMLElementType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("scene: unknown element type %q", text)
+}
@@ -0,0 +1,339 @@
+package scene
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"engo/pkg/layout"
+	"engo/pkg/style"
+)
+
+const sampleDocument = `{
+	"version": 1,
+	"root": {
+		"id": 1,
+		"type": "PAGE",
+		"children": [
+			{
+				"id": 2,
+				"type": "FRAME",
+				"style": { "left": 10, "top": "20px", "width": 200, "height": 100, "padding": { "top": 4, "left": "5%" } },
+				"props": { "cornerRadius": [4, 4, 4, 4], "fill": 4278190335 },
+				"children": [
+					{
+						"id": 3,
+						"type": "TEXT",
+						"element": "BUTTON",
+						"style": { "width": "50%", "height": "auto", "margin": 8, "opacity": 0.5 },
+						"props": { "content": "Hello", "fontSize": 14 }
+					}
+				]
+			}
+		]
+	}
+}`
+
+func TestUnmarshalDocument(t *testing.T) {
+	root, err := UnmarshalDocument([]byte(sampleDocument))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if root.Type != Page || root.ID != 1 || len(root.Children) != 1 {
+		t.Fatalf("Wrong root, got %+v", root)
+	}
+
+	frame := root.Children[0]
+	if frame.Parent != root || frame.Type != Frame {
+		t.Errorf("Frame not linked to root")
+	}
+	if frame.Style.Left != layout.Px(10) || frame.Style.Top != layout.Px(20) || frame.Style.Width != layout.Px(200) {
+		t.Errorf("Wrong frame style, got %+v", frame.Style)
+	}
+	if frame.Style.Padding.Top != layout.Px(4) || frame.Style.Padding.Left != layout.Pct(5) ||
+		frame.Style.Padding.Right != layout.Px(0) {
+		t.Errorf("Wrong frame padding, got %+v", frame.Style.Padding)
+	}
+	// Missing keys take the defaults of style.NewStyle
+	if frame.Style.Right != layout.Auto() || frame.Style.MaxWidth != layout.Auto() {
+		t.Errorf("Missing keys should default to auto, got %+v", frame.Style)
+	}
+	if frame.Style.Opacity != 1 {
+		t.Errorf("Opacity should default to 1, got %v", frame.Style.Opacity)
+	}
+
+	rect, ok := frame.Props.(*RectProps)
+	if !ok || rect.Fill != 0xFF0000FF || rect.CornerRadius[2] != 4 {
+		t.Errorf("Wrong frame props, got %+v", frame.Props)
+	}
+
+	text := frame.Children[0]
+	if text.HTMLElementType != Button {
+		t.Errorf("Expected BUTTON element, got %v", text.HTMLElementType)
+	}
+	if text.Style.Width != layout.Pct(50) || text.Style.Height != layout.Auto() || text.Style.Opacity != 0.5 {
+		t.Errorf("Wrong text style, got %+v", text.Style)
+	}
+	if text.Style.Margin != style.UniformEdges(layout.Px(8)) {
+		t.Errorf("A single margin should apply to every side, got %+v", text.Style.Margin)
+	}
+	// 50% of parent's 200px
+	if _, _, w, _ := text.Box(); w != 100 {
+		t.Errorf("Percent width should resolve against the parent, got %v", w)
+	}
+	if props, ok := text.Props.(*TextProps); !ok || props.Content != "Hello" {
+		t.Errorf("Wrong text props, got %+v", text.Props)
+	}
+
+	if text.Flags&FlagLayoutDirty == 0 {
+		t.Errorf("Loaded nodes must be dirty for the first reconcile")
+	}
+}
+
+func TestUnmarshalDocument_Invalid(t *testing.T) {
+	cases := map[string]struct {
+		doc string
+		err error
+	}{
+		"future version": {`{"version": 99, "root": {"id": 1, "type": "PAGE"}}`, ErrUnsupportedVersion},
+		"frame root":     {`{"version": 1, "root": {"id": 1, "type": "FRAME"}}`, ErrInvalidRoot},
+		"nested page": {
+			`{"version": 1, "root": {"id": 1, "type": "PAGE", "children": [{"id": 2, "type": "PAGE"}]}}`,
+			ErrInvalidRoot,
+		},
+		"duplicate id": {
+			`{"version": 1, "root": {"id": 1, "type": "PAGE", "children": [{"id": 1, "type": "FRAME"}]}}`,
+			ErrDuplicateID,
+		},
+	}
+
+	for name, c := range cases {
+		_, err := UnmarshalDocument([]byte(c.doc))
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: expected %v, got %v", name, c.err, err)
+		}
+	}
+
+	if _, err := UnmarshalDocument([]byte(`{"version": 1, "root": {"id": 1, "type": "CIRCLE"}}`)); err == nil {
+		t.Errorf("Unknown node type should fail")
+	}
+}
+
+// randomLength returns a px, percent or auto Dimension
+func randomLength(r *rand.Rand, max float32) layout.Dimension {
+	switch r.Intn(4) {
+	case 0:
+		return layout.Pct(float32(r.Intn(100)))
+	case 1:
+		return layout.Auto()
+	}
+	return layout.Px(r.Float32() * max)
+}
+
+// randomEdges returns the same length on every side, or one per side
+func randomEdges(r *rand.Rand) style.Edges {
+	if r.Intn(2) == 0 {
+		return style.UniformEdges(layout.Px(float32(r.Intn(32))))
+	}
+	return style.Edges{
+		Top:    layout.Px(float32(r.Intn(32))),
+		Right:  layout.Pct(float32(r.Intn(10))),
+		Bottom: layout.Px(0),
+		Left:   layout.Px(float32(r.Intn(32))),
+	}
+}
+
+// randomGrid returns grid properties with a few tracks of every unit
+func randomGrid(r *rand.Rand) layout.Grid {
+	tracks := func() []layout.Track {
+		var out []layout.Track
+		for range r.Intn(4) {
+			out = append(out, layout.Track{Value: float32(r.Intn(200)), Unit: layout.TrackUnit(r.Intn(4))})
+		}
+		return out
+	}
+
+	return layout.Grid{
+		Columns:      tracks(),
+		Rows:         tracks(),
+		RowGap:       float32(r.Intn(16)),
+		JustifyItems: layout.AlignItem(r.Intn(4)),
+	}
+}
+
+// randomTree builds a page with random nodes, styles and props
+func randomTree(r *rand.Rand, maxDepth, maxChildren int) *Node {
+	nextID := uint32(1)
+
+	var build func(nodeType NodeType, depth int) *Node
+	build = func(nodeType NodeType, depth int) *Node {
+		node := NewNode(nodeType, nil)
+		node.ID = nextID
+		nextID++
+
+		node.HTMLElementType = HTMLElementType(r.Intn(len(htmlElementTypeNames)))
+		node.Style = &style.Style{
+			Width:       randomLength(r, 1000),
+			Height:      randomLength(r, 1000),
+			Top:         randomLength(r, 200),
+			Right:       randomLength(r, 200),
+			Left:        layout.Px(r.Float32()*200 - 100),
+			Margin:      randomEdges(r),
+			Padding:     randomEdges(r),
+			BorderWidth: style.UniformEdges(layout.Px(float32(r.Intn(4)))),
+			Position:    style.Position(r.Intn(4)),
+			Overflow:    style.Overflow(r.Intn(2)),
+			Opacity:     r.Float32(),
+			Layout:      layout.Mode(r.Intn(3)),
+			Flex: layout.Flex{
+				Direction:      layout.Direction(r.Intn(4)),
+				JustifyContent: layout.JustifyContent(r.Intn(6)),
+				AlignItem:      layout.AlignItem(r.Intn(4)),
+				Grow:           int8(r.Intn(3)),
+				Shrink:         int8(r.Intn(3) - 1),
+				Wrap:           layout.Wrap(r.Intn(3)),
+				AlignContent:   layout.AlignContent(r.Intn(7)),
+				RowGap:         float32(r.Intn(16)),
+				ColumnGap:      float32(r.Intn(16)),
+			},
+		}
+		if r.Intn(2) == 0 {
+			node.Style.Grid = randomGrid(r)
+		}
+		bounds := []layout.Dimension{{}, layout.Auto(), layout.Pct(50), layout.Px(300)}
+		node.Style.MinWidth = layout.Px(float32(r.Intn(50)))
+		node.Style.MaxWidth = bounds[r.Intn(len(bounds))]
+		node.Style.MaxHeight = bounds[r.Intn(len(bounds))]
+		node.Style.AspectRatio = float32(r.Intn(4)) / 2
+		node.Style.GridItem = layout.GridItem{
+			Column:     int32(r.Intn(4)),
+			Row:        int32(r.Intn(4)),
+			ColumnSpan: int32(r.Intn(3)),
+		}
+		node.Style.Constraints = style.Constraints{
+			Horizontal: style.Constraint(r.Intn(5)),
+			Vertical:   style.Constraint(r.Intn(5)),
+		}
+		node.Style.Rotation = float32(r.Intn(8)) * 45
+		node.Hidden = r.Intn(4) == 0
+		node.Locked = r.Intn(4) == 0
+
+		switch props := NewProps(nodeType).(type) {
+		case *RectProps:
+			props.Fill = r.Uint32()
+			props.StrokeWidth = r.Float32() * 4
+			props.CornerRadius = [4]float32{r.Float32(), 0, r.Float32(), 8}
+			props.Shape = Shape(r.Intn(3))
+			if props.Shape == ShapePath {
+				props.Path = []float32{0.5, 0, 1, 1, 0, r.Float32()}
+			}
+			node.Props = props
+		case *TextProps:
+			props.Content = "Text \"quoted\" <tag> ✓"
+			props.FontSize = float32(8 + r.Intn(64))
+			props.LineHeight = float32(r.Intn(3)) / 2
+			props.LetterSpacing = r.Float32()
+			node.Props = props
+		case *ImageProps:
+			props.SourceURL = "https://example.com/a.png?x=1&y=2"
+			props.TextureID = r.Uint32()
+			node.Props = props
+		}
+
+		if depth < maxDepth {
+			for range r.Intn(maxChildren + 1) {
+				// Skip Page, only the root can be a page
+				childType := NodeType(1 + r.Intn(len(nodeTypeNames)-1))
+				node.AppendChild(build(childType, depth+1))
+			}
+		}
+
+		return node
+	}
+
+	return build(Page, 0)
+}
+
+func TestDocument_RoundTrip(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		root := randomTree(r, 4, 4)
+
+		first, err := MarshalDocument(root)
+		if err != nil {
+			t.Fatalf("seed %d: marshal failed: %v", seed, err)
+		}
+
+		loaded, err := UnmarshalDocument(first)
+		if err != nil {
+			t.Fatalf("seed %d: unmarshal failed: %v", seed, err)
+		}
+
+		second, err := MarshalDocument(loaded)
+		if err != nil {
+			t.Fatalf("seed %d: second marshal failed: %v", seed, err)
+		}
+
+		if !bytes.Equal(first, second) {
+			t.Errorf("seed %d: export is not stable\nfirst:  %s\nsecond: %s", seed, first, second)
+		}
+	}
+}
+
+func TestMarshalDocument_Rounding(t *testing.T) {
+	root := NewNode(Page, nil)
+	root.ID = 1
+	frame := NewNode(Frame, nil)
+	frame.ID = 2
+	frame.Style = style.NewStyle()
+	frame.Style.Left = layout.Px(10.005)
+	frame.Style.Width = layout.Pct(33.333)
+	root.AppendChild(frame)
+
+	first, _ := MarshalDocument(root)
+	loaded, err := UnmarshalDocument(first)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// First export keeps 2 decimals only
+	got := loaded.Children[0].Style
+	if got.Left != layout.Px(10.01) || got.Width != layout.Pct(33.33) {
+		t.Errorf("Expected 10.01 and 33.33%%, got %v and %v", got.Left, got.Width)
+	}
+
+	second, _ := MarshalDocument(loaded)
+	if !bytes.Equal(first, second) {
+		t.Errorf("Rounded export is not stable\nfirst:  %s\nsecond: %s", first, second)
+	}
+}
+
+func TestMarshalDocument_Defaults(t *testing.T) {
+	// Nodes fresh from NewNode have neither style nor props
+	root := NewNode(Page, nil)
+	root.ID = 1
+	frame := NewNode(Frame, nil)
+	frame.ID = 2
+	root.AppendChild(frame)
+
+	first, err := MarshalDocument(root)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	loaded, err := UnmarshalDocument(first)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	second, _ := MarshalDocument(loaded)
+	if !bytes.Equal(first, second) {
+		t.Errorf("Export is not stable\nfirst:  %s\nsecond: %s", first, second)
+	}
+
+	if _, err := MarshalDocument(frame); !errors.Is(err, ErrInvalidRoot) {
+		t.Errorf("Expected ErrInvalidRoot, got %v", err)
+	}
+}
@@ -1,6 +1,13 @@
 package scene
 
-import "engo/pkg/style"
+import (
+	"math"
+
+	"engo/internal/algo/rtree"
+	"engo/internal/protocol"
+	"engo/pkg/layout"
+	"engo/pkg/style"
+)
 
 type NodeType int
 
@@ -68,15 +75,27 @@ type Node struct {
 	Style         *style.Style
 	ComputedStyle *style.Style
 
+	// RectProps, TextProps or ImageProps depend on Type
+	Props Props
+
+	// Hidden nodes are neither drawn nor hit, locked nodes are drawn but
+	// can't be hit. Both apply to the whole subtree.
+	Hidden, Locked bool
+
+	// Scroll offset of the children when Style.Overflow is
+	// style.OverflowScroll. Runtime state, not saved with the document.
+	ScrollX, ScrollY float32
+
+	// World bounds of the node at the time it was inserted into
+	// the spatial index, needed to delete it from the R-tree later
+	LastWorldMBR rtree.Rect
+
 	Flags NodeFlag
 }
 
-var counter uint32 = 0
-
+// NewNode leaves ID 0 for the owner of the scene graph
+// to assign with its IDAllocator
 func NewNode(nodeType NodeType, parent *Node) *Node {
-	// Increase ID
-	counter = counter + 1
-
 	return &Node{
 		Parent:          parent,
 		Type:            nodeType,
@@ -168,16 +187,29 @@ func (n *Node) Contains(node *Node) bool {
 	return false
 }
 
-func (n *Node) Clone() *Node {
+// Clone deep copies n and its descendants, every copy gets a fresh ID
+// from ids. The clone is detached, Parent is nil.
+func (n *Node) Clone(ids *IDAllocator) *Node {
 	clone := &Node{
-		// the ID should not the same
-		// maybe will add a global incremental ID generator later
-		ID:   n.ID + 1,
-		Type: n.Type,
+		ID:              ids.Next(),
+		Type:            n.Type,
+		HTMLElementType: n.HTMLElementType,
+		Hidden:          n.Hidden,
+		Locked:          n.Locked,
+		Flags:           FlagContentDirty | FlagLayoutDirty,
+	}
+
+	if n.Style != nil {
+		s := *n.Style
+		s.Grid = s.Grid.Clone()
+		clone.Style = &s
+	}
+	if n.Props != nil {
+		clone.Props = n.Props.Clone()
 	}
 
 	for _, child := range n.Children {
-		childClone := child.Clone()
+		childClone := child.Clone(ids)
 		childClone.Parent = clone
 		clone.Children = append(clone.Children, childClone)
 	}
@@ -185,6 +217,60 @@ func (n *Node) Clone() *Node {
 	return clone
 }
 
+// Box returns position (relative to parent) and size of the node,
+// from the last layout pass if any, otherwise straight from Style.
+// Percentages of Style resolve against the parent box, auto is 0.
+func (n *Node) Box() (x, y, w, h float32) {
+	s := n.ComputedStyle
+	if s == nil {
+		s = n.Style
+	}
+	if s == nil {
+		return 0, 0, 0, 0
+	}
+
+	var parentW, parentH float32
+	if n.Parent != nil && hasPercent(s.Left, s.Top, s.Width, s.Height) {
+		_, _, parentW, parentH = n.Parent.Box()
+	}
+
+	return s.Left.Resolve(parentW), s.Top.Resolve(parentH), s.Width.Resolve(parentW), s.Height.Resolve(parentH)
+}
+
+func hasPercent(dims ...layout.Dimension) bool {
+	for _, d := range dims {
+		if d.Unit == layout.UnitPercent {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLocalMatrix returns transform of node relative to its parent,
+// children of a scroll container move by its scroll offset
+func (n *Node) GetLocalMatrix() protocol.Matrix {
+	x, y, w, h := n.Box()
+	if p := n.Parent; p != nil && p.IsScrollContainer() {
+		x -= p.ScrollX
+		y -= p.ScrollY
+	}
+
+	local := protocol.TranslateMatrix(x, y)
+	if n.Style != nil && n.Style.Rotation != 0 {
+		// Around the center, the box keeps its layout position
+		rad := float64(n.Style.Rotation) * math.Pi / 180
+		local = local.
+			Multiply(protocol.TranslateMatrix(w/2, h/2)).
+			Multiply(protocol.RotateMatrix(rad)).
+			Multiply(protocol.TranslateMatrix(-w/2, -h/2))
+	}
+	return local
+}
+
+func (n *Node) IsScrollContainer() bool {
+	return n.Style != nil && n.Style.Overflow == style.OverflowScroll
+}
+
 func (n *Node) HasChildNodes() bool {
 	return len(n.Children) > 0
 }
@@ -1,26 +1,86 @@
 package scene
 
+import "slices"
+
 type Props interface {
 	Clone() Props
 }
 
+// Shape is the outline of a node with RectProps inside its box
+type Shape uint8
+
+const (
+	// Box rounded by CornerRadius
+	ShapeRect Shape = iota
+	// Ellipse touching the four sides of the box
+	ShapeEllipse
+	// Closed polygon of Path
+	ShapePath
+)
+
 type RectProps struct {
-	CornerRadius [4]float32
-	Fill         uint32 // Màu RGBA
-	Stroke       uint32
-	StrokeWidth  float32
+	CornerRadius [4]float32 `json:"cornerRadius"`
+	Fill         uint32     `json:"fill"` // Màu RGBA
+	Stroke       uint32     `json:"stroke"`
+	StrokeWidth  float32    `json:"strokeWidth"`
+	Shape        Shape      `json:"shape"`
+	// [x0, y0, x1, y1, ...] as fractions of the box so the outline
+	// follows resizes, ShapePath only
+	Path []float32 `json:"path,omitempty"`
 }
 
+// Horizontal alignment of the lines of TextProps in the box
+const (
+	TextAlignLeft uint8 = iota
+	TextAlignCenter
+	TextAlignRight
+)
+
 type TextProps struct {
-	Content    string
-	FontFamily string
-	FontSize   float32
-	Fill       uint32
-	Align      uint8
+	Content    string  `json:"content"`
+	FontFamily string  `json:"fontFamily"`
+	FontSize   float32 `json:"fontSize"`
+	Fill       uint32  `json:"fill"`
+	Align      uint8   `json:"align"`
+	// Multiple of FontSize, 0 uses the font's own line height
+	LineHeight float32 `json:"lineHeight"`
+	// Pixels added after every character
+	LetterSpacing float32 `json:"letterSpacing"`
 }
 
 type ImageProps struct {
-	SourceURL string
-	TextureID uint32
-	ScaleMode uint8
+	SourceURL string `json:"sourceUrl"`
+	TextureID uint32 `json:"textureId"`
+	ScaleMode uint8  `json:"scaleMode"`
+}
+
+func (p *RectProps) Clone() Props {
+	clone := *p
+	clone.Path = slices.Clone(p.Path)
+	return &clone
+}
+
+func (p *TextProps) Clone() Props {
+	clone := *p
+	return &clone
+}
+
+func (p *ImageProps) Clone() Props {
+	clone := *p
+	return &clone
+}
+
+// NewProps returns empty props matching the node type,
+// nil for node types that don't carry any props
+func NewProps(nodeType NodeType) Props {
+	switch nodeType {
+	case Frame, Polygon, Line, Vector, Component, Instance, Sticky:
+		return &RectProps{}
+	case Text:
+		return &TextProps{}
+	case Image:
+		return &ImageProps{}
+	}
+
+	return nil
 }
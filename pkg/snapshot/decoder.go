@@ -0,0 +1,343 @@
+package snapshot
+
+import (
+	"fmt"
+	"math"
+
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+type decoder struct {
+	words   []uint32
+	strings []string
+}
+
+// Decode rebuilds the scene graph from a snapshot, migrating older major
+// versions first. It returns the page node, with every node flagged
+// dirty like scene.UnmarshalDocument.
+func Decode(words []uint32) (*scene.Node, error) {
+	if len(words) < 2 {
+		return nil, ErrCorrupted
+	}
+	if words[0] != Magic {
+		return nil, ErrInvalidMagic
+	}
+
+	words, err := migrate(words)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(words) < HeaderWords {
+		return nil, ErrCorrupted
+	}
+
+	var (
+		headerWords  = words[2]
+		nodeCount    = words[3]
+		recordWords  = words[4]
+		stringOffset = words[5]
+		stringCount  = words[6]
+		nodeOffset   = words[7]
+		propOffset   = words[8]
+		totalWords   = words[9]
+	)
+
+	if headerWords < HeaderWords || recordWords < minRecordWords ||
+		int(totalWords) > len(words) || nodeCount == 0 {
+		return nil, ErrCorrupted
+	}
+	// Only trust the size written in header, the buffer may be larger
+	d := &decoder{words: words[:totalWords]}
+
+	if err := d.readStrings(stringOffset, stringCount); err != nil {
+		return nil, err
+	}
+
+	if uint64(nodeOffset)+uint64(nodeCount)*uint64(recordWords) > uint64(len(d.words)) {
+		return nil, ErrCorrupted
+	}
+
+	nodes := make([]*scene.Node, nodeCount)
+	seen := make(map[uint32]bool, nodeCount)
+
+	for i := range nodes {
+		record := d.words[nodeOffset+uint32(i)*recordWords:]
+
+		id := record[0]
+		nodeType := scene.NodeType(record[1])
+		element := scene.HTMLElementType(record[2])
+		parent := record[3]
+
+		if id == 0 || seen[id] {
+			return nil, fmt.Errorf("%w: node id %d", ErrCorrupted, id)
+		}
+		seen[id] = true
+
+		if !nodeType.IsValid() || !element.IsValid() {
+			return nil, fmt.Errorf("%w: node %d type", ErrCorrupted, id)
+		}
+
+		// Page is the first record, and the only one
+		if (i == 0) != (nodeType == scene.Page) {
+			return nil, scene.ErrInvalidRoot
+		}
+		if (i == 0) != (parent == NoParent) || (i > 0 && parent >= uint32(i)) {
+			return nil, fmt.Errorf("%w: node %d parent", ErrCorrupted, id)
+		}
+
+		node := scene.NewNode(nodeType, nil)
+		node.ID = id
+		node.HTMLElementType = element
+		node.Style = style.NewStyle()
+		node.Props = scene.NewProps(nodeType)
+		node.Flags = scene.FlagContentDirty | scene.FlagLayoutDirty | scene.FlagSubtreeDirty
+		if recordWords > minRecordWords {
+			node.Hidden = record[6]&RecordHidden != 0
+			node.Locked = record[6]&RecordLocked != 0
+		}
+
+		if err := d.readBlocks(node, propOffset, record[4], record[5]); err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			nodes[parent].AppendChild(node)
+		}
+		nodes[i] = node
+	}
+
+	return nodes[0], nil
+}
+
+func (d *decoder) readStrings(offset, count uint32) error {
+	pos := uint64(offset)
+	// Every string takes at least its length word
+	if pos+uint64(count) > uint64(len(d.words)) {
+		return ErrCorrupted
+	}
+	d.strings = make([]string, 0, count)
+
+	for range count {
+		if pos >= uint64(len(d.words)) {
+			return ErrCorrupted
+		}
+
+		length := uint64(d.words[pos])
+		pos++
+
+		wordCount := (length + 3) / 4
+		if pos+wordCount > uint64(len(d.words)) {
+			return ErrCorrupted
+		}
+
+		buf := make([]byte, length)
+		for i := range buf {
+			word := d.words[pos+uint64(i/4)]
+			buf[i] = byte(word >> (8 * (i % 4)))
+		}
+
+		d.strings = append(d.strings, string(buf))
+		pos += wordCount
+	}
+
+	return nil
+}
+
+func (d *decoder) str(idx uint32) (string, error) {
+	if int(idx) >= len(d.strings) {
+		return "", fmt.Errorf("%w: string %d", ErrCorrupted, idx)
+	}
+	return d.strings[idx], nil
+}
+
+func (d *decoder) readBlocks(node *scene.Node, propOffset, start, length uint32) error {
+	begin := uint64(propOffset) + uint64(start)
+	end := begin + uint64(length)
+	if end > uint64(len(d.words)) {
+		return ErrCorrupted
+	}
+
+	blocks := d.words[begin:end]
+	for len(blocks) > 0 {
+		tag, size := parseBlockHeader(blocks[0])
+		if 1+size > len(blocks) {
+			return fmt.Errorf("%w: node %d block", ErrCorrupted, node.ID)
+		}
+
+		if err := d.readBlock(node, tag, blocks[1:1+size]); err != nil {
+			return err
+		}
+
+		blocks = blocks[1+size:]
+	}
+
+	return nil
+}
+
+// readBlock decodes one property block. Unknown tags and trailing words
+// come from newer minor versions and are skipped, missing words keep
+// their defaults.
+func (d *decoder) readBlock(node *scene.Node, tag BlockTag, payload []uint32) error {
+	// Pad to the known size so older, shorter blocks read as zero
+	word := func(i int) uint32 {
+		if i < len(payload) {
+			return payload[i]
+		}
+		return 0
+	}
+
+	switch tag {
+	case BlockStyle:
+		dimension := func(i int) layout.Dimension {
+			return layout.Dimension{Unit: layout.Unit(word(i)), Value: readFloat(word(i + 1))}
+		}
+		edges := func(i int) style.Edges {
+			return style.Edges{Top: dimension(i), Right: dimension(i + 2), Bottom: dimension(i + 4), Left: dimension(i + 6)}
+		}
+
+		s := node.Style
+		s.Width = dimension(0)
+		s.Height = dimension(2)
+		s.Top = dimension(4)
+		s.Right = dimension(6)
+		s.Bottom = dimension(8)
+		s.Left = dimension(10)
+		s.Margin = edges(12)
+		s.Padding = edges(20)
+		s.BorderWidth = edges(28)
+		s.Position = style.Position(word(36))
+		s.Overflow = style.Overflow(word(37))
+		s.Opacity = readFloat(word(38))
+		s.Layout = layout.Mode(word(39))
+		s.Flex = layout.Flex{
+			Direction:      layout.Direction(word(40)),
+			JustifyContent: layout.JustifyContent(word(41)),
+			AlignItem:      layout.AlignItem(word(42)),
+			Grow:           int8(int32(word(43))),
+			Shrink:         int8(int32(word(44))),
+			Wrap:           layout.Wrap(word(45)),
+			AlignContent:   layout.AlignContent(word(46)),
+			RowGap:         readFloat(word(47)),
+			ColumnGap:      readFloat(word(48)),
+		}
+		s.GridItem = layout.GridItem{
+			Column:     int32(word(49)),
+			Row:        int32(word(50)),
+			ColumnSpan: int32(word(51)),
+			RowSpan:    int32(word(52)),
+		}
+		s.MinWidth = dimension(53)
+		s.MaxWidth = dimension(55)
+		s.MinHeight = dimension(57)
+		s.MaxHeight = dimension(59)
+		s.AspectRatio = readFloat(word(61))
+		s.Constraints = style.Constraints{
+			Horizontal: style.Constraint(word(62)),
+			Vertical:   style.Constraint(word(63)),
+		}
+		s.Rotation = readFloat(word(64))
+	case BlockGrid:
+		grid, err := readGrid(payload)
+		if err != nil {
+			return err
+		}
+		node.Style.Grid = grid
+	case BlockRect:
+		p, ok := node.Props.(*scene.RectProps)
+		if !ok {
+			return nil
+		}
+		for i := range p.CornerRadius {
+			p.CornerRadius[i] = readFloat(word(i))
+		}
+		p.Fill = word(4)
+		p.Stroke = word(5)
+		p.StrokeWidth = readFloat(word(6))
+		p.Shape = scene.Shape(word(7))
+	case BlockPath:
+		p, ok := node.Props.(*scene.RectProps)
+		if !ok {
+			return nil
+		}
+		p.Path = make([]float32, len(payload))
+		for i, w := range payload {
+			p.Path[i] = readFloat(w)
+		}
+	case BlockText:
+		p, ok := node.Props.(*scene.TextProps)
+		if !ok {
+			return nil
+		}
+		content, err := d.str(word(0))
+		if err != nil {
+			return err
+		}
+		family, err := d.str(word(1))
+		if err != nil {
+			return err
+		}
+		p.Content = content
+		p.FontFamily = family
+		p.FontSize = readFloat(word(2))
+		p.Fill = word(3)
+		p.Align = uint8(word(4))
+		p.LineHeight = readFloat(word(5))
+		p.LetterSpacing = readFloat(word(6))
+	case BlockImage:
+		p, ok := node.Props.(*scene.ImageProps)
+		if !ok {
+			return nil
+		}
+		url, err := d.str(word(0))
+		if err != nil {
+			return err
+		}
+		p.SourceURL = url
+		p.TextureID = word(1)
+		p.ScaleMode = uint8(word(2))
+	}
+
+	return nil
+}
+
+func readFloat(word uint32) float32 {
+	return math.Float32frombits(word)
+}
+
+func readGrid(payload []uint32) (layout.Grid, error) {
+	if len(payload) < 6 {
+		return layout.Grid{}, ErrCorrupted
+	}
+
+	g := layout.Grid{
+		RowGap:       readFloat(payload[0]),
+		ColumnGap:    readFloat(payload[1]),
+		JustifyItems: layout.AlignItem(payload[2]),
+		AlignItems:   layout.AlignItem(payload[3]),
+	}
+
+	cols, rows := int(payload[4]), int(payload[5])
+	tracks := payload[6:]
+	if cols > len(tracks)/2 || rows > len(tracks)/2-cols {
+		return layout.Grid{}, ErrCorrupted
+	}
+
+	read := func(count int) []layout.Track {
+		if count == 0 {
+			return nil
+		}
+		out := make([]layout.Track, count)
+		for i := range out {
+			out[i] = layout.Track{Unit: layout.TrackUnit(tracks[0]), Value: readFloat(tracks[1])}
+			tracks = tracks[2:]
+		}
+		return out
+	}
+	g.Columns = read(cols)
+	g.Rows = read(rows)
+
+	return g, nil
+}
@@ -0,0 +1,239 @@
+package snapshot
+
+import (
+	"engo/internal/protocol"
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+type encoder struct {
+	buf *protocol.CommandBuffer
+
+	strings     []string
+	stringIndex map[string]uint32
+}
+
+// Encode serializes the scene graph under root (a page node)
+// into a snapshot of the current Version
+func Encode(root *scene.Node) ([]uint32, error) {
+	if root == nil || root.Type != scene.Page {
+		return nil, scene.ErrInvalidRoot
+	}
+
+	var nodes []*scene.Node
+	var collect func(n *scene.Node)
+	collect = func(n *scene.Node) {
+		nodes = append(nodes, n)
+		for _, child := range n.Children {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	e := &encoder{
+		stringIndex: make(map[string]uint32),
+	}
+	e.intern("")
+
+	// Property blocks first, they also fill the string table
+	props := protocol.NewCommandBufferWithSize(len(nodes) * 24)
+	ranges := make([][2]uint32, len(nodes))
+	for i, node := range nodes {
+		start := props.GetSize()
+		e.writeStyle(props, node.Style)
+		e.writeProps(props, node)
+		ranges[i] = [2]uint32{uint32(start), uint32(props.GetSize() - start)}
+	}
+
+	e.buf = protocol.NewCommandBufferWithSize(HeaderWords + len(nodes)*NodeRecordWords + props.GetSize())
+	for range HeaderWords {
+		e.buf.WriteUint(0)
+	}
+
+	stringOffset := e.buf.GetSize()
+	for _, s := range e.strings {
+		e.writeString(s)
+	}
+
+	nodeOffset := e.buf.GetSize()
+	parentIndex := make(map[*scene.Node]uint32, len(nodes))
+	for i, node := range nodes {
+		parentIndex[node] = uint32(i)
+
+		parent := NoParent
+		if i > 0 {
+			parent = parentIndex[node.Parent]
+		}
+
+		e.buf.WriteUint(node.ID)
+		e.buf.WriteUint(uint32(node.Type))
+		e.buf.WriteUint(uint32(node.HTMLElementType))
+		e.buf.WriteUint(parent)
+		e.buf.WriteUint(ranges[i][0])
+		e.buf.WriteUint(ranges[i][1])
+		e.buf.WriteUint(recordState(node))
+	}
+
+	propOffset := e.buf.GetSize()
+	e.buf.Data = append(e.buf.Data, props.Data...)
+
+	copy(e.buf.Data, []uint32{
+		Magic,
+		Version,
+		HeaderWords,
+		uint32(len(nodes)),
+		NodeRecordWords,
+		uint32(stringOffset),
+		uint32(len(e.strings)),
+		uint32(nodeOffset),
+		uint32(propOffset),
+		uint32(e.buf.GetSize()),
+	})
+
+	return e.buf.Data, nil
+}
+
+func (e *encoder) intern(s string) uint32 {
+	if idx, ok := e.stringIndex[s]; ok {
+		return idx
+	}
+
+	idx := uint32(len(e.strings))
+	e.strings = append(e.strings, s)
+	e.stringIndex[s] = idx
+	return idx
+}
+
+func (e *encoder) writeString(s string) {
+	e.buf.WriteUint(uint32(len(s)))
+
+	for i := 0; i < len(s); i += 4 {
+		var word uint32
+		for j := 0; j < 4 && i+j < len(s); j++ {
+			word |= uint32(s[i+j]) << (8 * j)
+		}
+		e.buf.WriteUint(word)
+	}
+}
+
+func (e *encoder) writeStyle(buf *protocol.CommandBuffer, s *style.Style) {
+	// Same default as a node loaded without style block
+	if s == nil {
+		s = style.NewStyle()
+	}
+
+	buf.WriteUint(blockHeader(BlockStyle, styleBlockWords))
+	for _, d := range []layout.Dimension{s.Width, s.Height, s.Top, s.Right, s.Bottom, s.Left} {
+		writeDimension(buf, d)
+	}
+	for _, edges := range []style.Edges{s.Margin, s.Padding, s.BorderWidth} {
+		for _, d := range []layout.Dimension{edges.Top, edges.Right, edges.Bottom, edges.Left} {
+			writeDimension(buf, d)
+		}
+	}
+	buf.WriteUint(uint32(s.Position))
+	buf.WriteUint(uint32(s.Overflow))
+	buf.WriteFloat(s.Opacity)
+	buf.WriteUint(uint32(s.Layout))
+	buf.WriteUint(uint32(s.Flex.Direction))
+	buf.WriteUint(uint32(s.Flex.JustifyContent))
+	buf.WriteUint(uint32(s.Flex.AlignItem))
+	buf.WriteUint(uint32(int32(s.Flex.Grow)))
+	buf.WriteUint(uint32(int32(s.Flex.Shrink)))
+	buf.WriteUint(uint32(s.Flex.Wrap))
+	buf.WriteUint(uint32(s.Flex.AlignContent))
+	buf.WriteFloat(s.Flex.RowGap)
+	buf.WriteFloat(s.Flex.ColumnGap)
+	buf.WriteUint(uint32(s.GridItem.Column))
+	buf.WriteUint(uint32(s.GridItem.Row))
+	buf.WriteUint(uint32(s.GridItem.ColumnSpan))
+	buf.WriteUint(uint32(s.GridItem.RowSpan))
+	for _, d := range []layout.Dimension{s.MinWidth, s.MaxWidth, s.MinHeight, s.MaxHeight} {
+		writeDimension(buf, d)
+	}
+	buf.WriteFloat(s.AspectRatio)
+	buf.WriteUint(uint32(s.Constraints.Horizontal))
+	buf.WriteUint(uint32(s.Constraints.Vertical))
+	buf.WriteFloat(s.Rotation)
+
+	if !s.Grid.IsZero() {
+		e.writeGrid(buf, s.Grid)
+	}
+}
+
+// writeDimension writes [unit, value]
+func writeDimension(buf *protocol.CommandBuffer, d layout.Dimension) {
+	buf.WriteUint(uint32(d.Unit))
+	buf.WriteFloat(d.Value)
+}
+
+func (e *encoder) writeGrid(buf *protocol.CommandBuffer, g layout.Grid) {
+	buf.WriteUint(blockHeader(BlockGrid, 6+2*(len(g.Columns)+len(g.Rows))))
+	buf.WriteFloat(g.RowGap)
+	buf.WriteFloat(g.ColumnGap)
+	buf.WriteUint(uint32(g.JustifyItems))
+	buf.WriteUint(uint32(g.AlignItems))
+	buf.WriteUint(uint32(len(g.Columns)))
+	buf.WriteUint(uint32(len(g.Rows)))
+	for _, tracks := range [][]layout.Track{g.Columns, g.Rows} {
+		for _, t := range tracks {
+			buf.WriteUint(uint32(t.Unit))
+			buf.WriteFloat(t.Value)
+		}
+	}
+}
+
+func (e *encoder) writeProps(buf *protocol.CommandBuffer, node *scene.Node) {
+	props := scene.NewProps(node.Type)
+	if props == nil {
+		return
+	}
+	if node.Props != nil {
+		props = node.Props
+	}
+
+	switch p := props.(type) {
+	case *scene.RectProps:
+		buf.WriteUint(blockHeader(BlockRect, 8))
+		for _, r := range p.CornerRadius {
+			buf.WriteFloat(r)
+		}
+		buf.WriteUint(p.Fill)
+		buf.WriteUint(p.Stroke)
+		buf.WriteFloat(p.StrokeWidth)
+		buf.WriteUint(uint32(p.Shape))
+
+		if len(p.Path) > 0 {
+			buf.WriteUint(blockHeader(BlockPath, len(p.Path)))
+			for _, v := range p.Path {
+				buf.WriteFloat(v)
+			}
+		}
+	case *scene.TextProps:
+		buf.WriteUint(blockHeader(BlockText, 7))
+		buf.WriteUint(e.intern(p.Content))
+		buf.WriteUint(e.intern(p.FontFamily))
+		buf.WriteFloat(p.FontSize)
+		buf.WriteUint(p.Fill)
+		buf.WriteUint(uint32(p.Align))
+		buf.WriteFloat(p.LineHeight)
+		buf.WriteFloat(p.LetterSpacing)
+	case *scene.ImageProps:
+		buf.WriteUint(blockHeader(BlockImage, 3))
+		buf.WriteUint(e.intern(p.SourceURL))
+		buf.WriteUint(p.TextureID)
+		buf.WriteUint(uint32(p.ScaleMode))
+	}
+}
+
+func recordState(node *scene.Node) uint32 {
+	var state uint32
+	if node.Hidden {
+		state |= RecordHidden
+	}
+	if node.Locked {
+		state |= RecordLocked
+	}
+	return state
+}
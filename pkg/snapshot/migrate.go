@@ -0,0 +1,144 @@
+package snapshot
+
+import (
+	"fmt"
+	"math"
+
+	"engo/pkg/layout"
+)
+
+// migration upgrades a snapshot of major version N into major version
+// N+1, including the version word of the header
+type migration func(words []uint32) ([]uint32, error)
+
+// migrations[N] upgrades major version N to N+1.
+// When bumping VersionMajor, register the upgrade from the previous
+// major here so old files keep opening.
+var migrations = map[uint32]migration{
+	1: migrateV1,
+}
+
+// migrate upgrades words step by step until it reaches VersionMajor.
+// Newer minor versions of the same major are left as is, the decoder
+// skips what it doesn't know.
+func migrate(words []uint32) ([]uint32, error) {
+	for {
+		major := words[1] >> 16
+
+		if major == VersionMajor {
+			return words, nil
+		}
+
+		m, ok := migrations[major]
+		if major > VersionMajor || !ok {
+			return nil, fmt.Errorf("%w: %d.%d", ErrUnsupportedVersion, major, words[1]&0xFFFF)
+		}
+
+		upgraded, err := m(words)
+		if err != nil {
+			return nil, err
+		}
+		if len(upgraded) < 2 || upgraded[1]>>16 != major+1 {
+			return nil, fmt.Errorf("snapshot: migration from %d didn't bump version", major)
+		}
+
+		words = upgraded
+	}
+}
+
+// migrateV1 rewrites the style blocks of a 1.x snapshot into the 2.0
+// layout: pixel floats become [UnitPixel, value] Dimensions and the
+// single margin, padding and border width apply to every side. Other
+// blocks are copied as is, the property region is rebuilt at the end.
+func migrateV1(words []uint32) ([]uint32, error) {
+	if len(words) < HeaderWords {
+		return nil, ErrCorrupted
+	}
+
+	var (
+		nodeCount   = words[3]
+		recordWords = words[4]
+		nodeOffset  = words[7]
+		propOffset  = words[8]
+		totalWords  = words[9]
+	)
+	if recordWords < minRecordWords || int(totalWords) > len(words) || propOffset > totalWords ||
+		uint64(nodeOffset)+uint64(nodeCount)*uint64(recordWords) > uint64(propOffset) {
+		return nil, ErrCorrupted
+	}
+
+	out := append([]uint32{}, words[:propOffset]...)
+	props := words[propOffset:totalWords]
+
+	for i := range nodeCount {
+		record := nodeOffset + i*recordWords
+		start, length := uint64(out[record+4]), uint64(out[record+5])
+		if start+length > uint64(len(props)) {
+			return nil, ErrCorrupted
+		}
+
+		newStart := uint32(len(out)) - propOffset
+		blocks := props[start : start+length]
+		for len(blocks) > 0 {
+			tag, size := parseBlockHeader(blocks[0])
+			if 1+size > len(blocks) {
+				return nil, ErrCorrupted
+			}
+
+			payload := blocks[1 : 1+size]
+			if tag == BlockStyle {
+				payload = migrateStyleV1(payload)
+			}
+			out = append(out, blockHeader(tag, len(payload)))
+			out = append(out, payload...)
+
+			blocks = blocks[1+size:]
+		}
+
+		// out may have grown, index it instead of keeping a slice
+		out[record+4] = newStart
+		out[record+5] = uint32(len(out)) - propOffset - newStart
+	}
+
+	out[1] = 2 << 16
+	out[9] = uint32(len(out))
+	return out, nil
+}
+
+// migrateStyleV1 converts a 1.4 style payload, older minors are shorter
+// and their missing words read as zero like in the decoder
+func migrateStyleV1(v1 []uint32) []uint32 {
+	word := func(i int) uint32 {
+		if i < len(v1) {
+			return v1[i]
+		}
+		return 0
+	}
+	px := func(i int) []uint32 {
+		return []uint32{uint32(layout.UnitPixel), word(i)}
+	}
+
+	opacity := math.Float32bits(1)
+	if len(v1) > 11 {
+		opacity = v1[11]
+	}
+
+	var v2 []uint32
+	// width, height, top, right, bottom, left
+	for i := range 6 {
+		v2 = append(v2, px(i)...)
+	}
+	// margin, padding, border width on every side
+	for i := 6; i < 9; i++ {
+		for range 4 {
+			v2 = append(v2, px(i)...)
+		}
+	}
+	v2 = append(v2, word(9), word(10), opacity)
+	// layout, flex, grid placement, min/max and aspect ratio are unchanged
+	for i := 12; i < 35; i++ {
+		v2 = append(v2, word(i))
+	}
+
+	return v2
+}
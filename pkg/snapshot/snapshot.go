@@ -0,0 +1,110 @@
+// Package snapshot implements the binary document format, a compact
+// alternative to the JSON document of scene.MarshalDocument for large files.
+//
+// A snapshot is a flat []uint32 (little-endian in WASM memory, same as
+// protocol.CommandBuffer) so JS can peek at it with a Uint32Array:
+//
+//	HEADER (HeaderWords words)
+//	  [0] Magic
+//	  [1] Version          (major << 16) | minor
+//	  [2] header words     readers skip to this offset, header may grow
+//	  [3] node count
+//	  [4] node record words (stride of the node table, may grow)
+//	  [5] string table offset
+//	  [6] string count
+//	  [7] node table offset
+//	  [8] property offset
+//	  [9] total words
+//
+//	STRING TABLE
+//	  per string: [byte length] [UTF-8 bytes padded to a word boundary]
+//	  index 0 is always the empty string
+//
+//	NODE TABLE, one fixed-size record per node in pre-order
+//	  [0] id
+//	  [1] scene.NodeType
+//	  [2] scene.HTMLElementType
+//	  [3] parent record index, NoParent for the page
+//	  [4] property blocks offset, relative to the property offset
+//	  [5] property blocks length in words
+//	  [6] node state bits, RecordHidden | RecordLocked
+//
+//	PROPERTY BLOCKS
+//	  per block: [(length << 8) | BlockTag] [length payload words]
+//	  header word has the same layout as a command buffer opcode header
+//
+// Minor versions only append header words, record words, block tags or
+// trailing block words, so readers skip what they don't know and older
+// engines can open newer files of the same major version. A major version
+// bump needs a migration, see migrate.go.
+package snapshot
+
+import "errors"
+
+// "ENGO" in little-endian
+const Magic uint32 = 0x4F474E45
+
+const (
+	// 2 stores lengths as Dimensions and the box model per side
+	// 2.1 appends the resize constraints to the style block,
+	// 2.2 line height and letter spacing to the text block,
+	// 2.3 rotation, shapes and the node state word
+	VersionMajor = 2
+	VersionMinor = 3
+
+	Version = VersionMajor<<16 | VersionMinor
+)
+
+const (
+	HeaderWords     = 10
+	NodeRecordWords = 7
+	// Records before 2.3 have no node state word
+	minRecordWords = 6
+
+	NoParent uint32 = 0xFFFFFFFF
+
+	styleBlockWords = 65
+)
+
+// Node state bits of the node record
+const (
+	RecordHidden uint32 = 1 << iota
+	RecordLocked
+)
+
+type BlockTag uint8
+
+const (
+	// Style fields, lengths are [unit, value] pairs: width, height,
+	// top, right, bottom, left, margin, padding and border width (4 sides
+	// each), then position, overflow, opacity, layout mode, flex (direction,
+	// justify content, align items, grow, shrink, wrap, align content, row
+	// gap, column gap), grid placement (column, row, column span, row span),
+	// min/max width/height, aspect ratio, constraints (horizontal,
+	// vertical) and rotation
+	BlockStyle BlockTag = 0x01
+	// [4 corner radii, fill, stroke, stroke width, shape]
+	BlockRect  BlockTag = 0x02
+	BlockText  BlockTag = 0x03
+	BlockImage BlockTag = 0x04
+	// Grid container properties, only written when set:
+	// [rowGap, columnGap, justifyItems, alignItems, column count,
+	// row count, (unit, value) per column then per row]
+	BlockGrid BlockTag = 0x05
+	// Points of a ShapePath, [x, y] pairs, only written when set
+	BlockPath BlockTag = 0x06
+)
+
+var (
+	ErrInvalidMagic       = errors.New("snapshot: invalid magic")
+	ErrUnsupportedVersion = errors.New("snapshot: unsupported version")
+	ErrCorrupted          = errors.New("snapshot: corrupted data")
+)
+
+func blockHeader(tag BlockTag, length int) uint32 {
+	return uint32(length)<<8 | uint32(tag)
+}
+
+func parseBlockHeader(header uint32) (BlockTag, int) {
+	return BlockTag(header & 0xFF), int(header >> 8)
+}
@@ -0,0 +1,334 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"engo/pkg/layout"
+	"engo/pkg/scene"
+	"engo/pkg/style"
+)
+
+// randomLength returns a px, percent or auto Dimension
+func randomLength(r *rand.Rand, max float32) layout.Dimension {
+	switch r.Intn(4) {
+	case 0:
+		return layout.Pct(float32(r.Intn(100)))
+	case 1:
+		return layout.Auto()
+	}
+	return layout.Px(r.Float32() * max)
+}
+
+// randomEdges returns the same length on every side, or one per side
+func randomEdges(r *rand.Rand) style.Edges {
+	if r.Intn(2) == 0 {
+		return style.UniformEdges(layout.Px(float32(r.Intn(32))))
+	}
+	return style.Edges{
+		Top:    layout.Px(float32(r.Intn(32))),
+		Right:  layout.Pct(float32(r.Intn(10))),
+		Bottom: layout.Px(0),
+		Left:   layout.Px(float32(r.Intn(32))),
+	}
+}
+
+// randomGrid returns grid properties with a few tracks of every unit
+func randomGrid(r *rand.Rand) layout.Grid {
+	tracks := func() []layout.Track {
+		var out []layout.Track
+		for range r.Intn(4) {
+			out = append(out, layout.Track{Value: float32(r.Intn(200)), Unit: layout.TrackUnit(r.Intn(4))})
+		}
+		return out
+	}
+
+	return layout.Grid{
+		Columns:      tracks(),
+		Rows:         tracks(),
+		RowGap:       float32(r.Intn(16)),
+		JustifyItems: layout.AlignItem(r.Intn(4)),
+	}
+}
+
+// randomPage builds a page with random nodes, styles and props
+func randomPage(r *rand.Rand, count int) *scene.Node {
+	root := scene.NewNode(scene.Page, nil)
+	root.ID = 1
+	nodes := []*scene.Node{root}
+
+	for i := 0; i < count; i++ {
+		nodeType := scene.NodeType(1 + r.Intn(int(scene.Sticky)))
+		node := scene.NewNode(nodeType, nil)
+		node.ID = uint32(i + 2)
+		node.HTMLElementType = scene.HTMLElementType(r.Intn(int(scene.Input) + 1))
+		node.Style = &style.Style{
+			Width:       randomLength(r, 1000),
+			Height:      randomLength(r, 1000),
+			Top:         randomLength(r, 200),
+			Bottom:      randomLength(r, 200),
+			Left:        layout.Px(r.Float32()*200 - 100),
+			Margin:      randomEdges(r),
+			Padding:     randomEdges(r),
+			BorderWidth: style.UniformEdges(layout.Px(float32(r.Intn(4)))),
+			Position:    style.Position(r.Intn(4)),
+			Opacity:     r.Float32(),
+			Layout:      layout.Mode(r.Intn(3)),
+			Flex: layout.Flex{
+				Direction:      layout.Direction(r.Intn(4)),
+				JustifyContent: layout.JustifyContent(r.Intn(6)),
+				AlignItem:      layout.AlignItem(r.Intn(4)),
+				Grow:           int8(r.Intn(3)),
+				Shrink:         int8(r.Intn(3) - 1),
+				Wrap:           layout.Wrap(r.Intn(3)),
+				AlignContent:   layout.AlignContent(r.Intn(7)),
+				RowGap:         float32(r.Intn(16)),
+				ColumnGap:      float32(r.Intn(16)),
+			},
+		}
+		if r.Intn(2) == 0 {
+			node.Style.Grid = randomGrid(r)
+		}
+		bounds := []layout.Dimension{{}, layout.Auto(), layout.Pct(50), layout.Px(300)}
+		node.Style.MinWidth = layout.Px(float32(r.Intn(50)))
+		node.Style.MaxWidth = bounds[r.Intn(len(bounds))]
+		node.Style.MaxHeight = bounds[r.Intn(len(bounds))]
+		node.Style.AspectRatio = float32(r.Intn(4)) / 2
+		node.Style.GridItem = layout.GridItem{
+			Column:     int32(r.Intn(4)),
+			Row:        int32(r.Intn(4)),
+			ColumnSpan: int32(r.Intn(3)),
+		}
+		node.Style.Constraints = style.Constraints{
+			Horizontal: style.Constraint(r.Intn(5)),
+			Vertical:   style.Constraint(r.Intn(5)),
+		}
+		node.Style.Rotation = r.Float32()*360 - 180
+		node.Hidden = r.Intn(4) == 0
+		node.Locked = r.Intn(4) == 0
+
+		switch p := scene.NewProps(nodeType).(type) {
+		case *scene.RectProps:
+			p.Fill = r.Uint32()
+			p.CornerRadius = [4]float32{r.Float32(), 1, 2, 3}
+			p.Shape = scene.Shape(r.Intn(3))
+			if p.Shape == scene.ShapePath {
+				p.Path = []float32{0.5, 0, 1, 1, 0, r.Float32()}
+			}
+			node.Props = p
+		case *scene.TextProps:
+			p.Content = string([]rune("Xin chào ✓ abcdefgh")[:r.Intn(18)])
+			p.FontFamily = "Inter"
+			p.FontSize = 16
+			p.LineHeight = float32(r.Intn(3)) / 2
+			p.LetterSpacing = r.Float32()
+			node.Props = p
+		case *scene.ImageProps:
+			p.SourceURL = "https://example.com/a.png"
+			p.TextureID = r.Uint32()
+			node.Props = p
+		}
+
+		nodes[r.Intn(len(nodes))].AppendChild(node)
+		nodes = append(nodes, node)
+	}
+
+	return root
+}
+
+func mustJSON(t *testing.T, root *scene.Node) []byte {
+	t.Helper()
+
+	data, err := scene.MarshalDocument(root)
+	if err != nil {
+		t.Fatalf("Marshal JSON failed: %v", err)
+	}
+	return data
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		root := randomPage(r, 200)
+
+		words, err := Encode(root)
+		if err != nil {
+			t.Fatalf("seed %d: encode failed: %v", seed, err)
+		}
+
+		if words[0] != Magic || words[9] != uint32(len(words)) {
+			t.Fatalf("seed %d: wrong header", seed)
+		}
+
+		decoded, err := Decode(words)
+		if err != nil {
+			t.Fatalf("seed %d: decode failed: %v", seed, err)
+		}
+
+		// Binary keeps exact floats, so both scenes export the same JSON
+		if !bytes.Equal(mustJSON(t, root), mustJSON(t, decoded)) {
+			t.Errorf("seed %d: decoded scene differs from source", seed)
+		}
+	}
+}
+
+func TestSnapshot_ForwardCompatible(t *testing.T) {
+	root := randomPage(rand.New(rand.NewSource(1)), 10)
+	words, _ := Encode(root)
+
+	// Simulate a newer minor version: one more header word
+	// and an unknown block appended to the page
+	newer := append([]uint32{}, words[:HeaderWords]...)
+	newer = append(newer, 0xCAFE)
+	// String, node and property offsets shift by that word
+	newer[5]++
+	newer[7]++
+	newer[8]++
+	newer = append(newer, words[HeaderWords:]...)
+
+	newer[1] = VersionMajor<<16 | (VersionMinor + 1)
+	newer[2] = HeaderWords + 1
+
+	// Move page blocks to the end, followed by the unknown block
+	page := newer[7]
+	start, length := newer[page+4], newer[page+5]
+	pageBlocks := append([]uint32{}, newer[newer[8]+start:newer[8]+start+length]...)
+
+	newer[page+4] = uint32(len(newer)) - newer[8]
+	newer[page+5] = length + 3
+	newer = append(newer, pageBlocks...)
+	newer = append(newer, blockHeader(0x7F, 2), 1, 2)
+	newer[9] = uint32(len(newer))
+
+	decoded, err := Decode(newer)
+	if err != nil {
+		t.Fatalf("Newer minor version should decode: %v", err)
+	}
+
+	if !bytes.Equal(mustJSON(t, root), mustJSON(t, decoded)) {
+		t.Errorf("Decoded scene differs from source")
+	}
+}
+
+// encodeV1 writes a 1.x snapshot by hand: a page with a 1.0 style
+// block and a frame with a 1.4 style block and rect props
+func encodeV1() []uint32 {
+	f := math.Float32bits
+
+	pageStyle := []uint32{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, f(1)}
+
+	frameStyle := make([]uint32, 35)
+	frameStyle[0] = f(100)  // width
+	frameStyle[1] = f(50)   // height
+	frameStyle[5] = f(10)   // left
+	frameStyle[6] = f(4)    // margin
+	frameStyle[7] = f(8)    // padding
+	frameStyle[11] = f(0.5) // opacity
+	frameStyle[12] = uint32(layout.ModeFlex)
+	frameStyle[26] = uint32(layout.UnitPercent) // min width
+	frameStyle[27] = f(50)
+	frameStyle[34] = f(2) // aspect ratio
+
+	props := []uint32{blockHeader(BlockStyle, len(pageStyle))}
+	props = append(props, pageStyle...)
+	props = append(props, blockHeader(BlockStyle, len(frameStyle)))
+	props = append(props, frameStyle...)
+	props = append(props, blockHeader(BlockRect, 7), f(4), f(4), f(4), f(4), 0xFF0000FF, 0, 0)
+
+	const strOffset, nodeOffset, propOffset = 10, 11, 23
+	words := []uint32{Magic, 1<<16 | 4, 10, 2, 6, strOffset, 1, nodeOffset, propOffset, 0}
+	words = append(words, 0) // "" string
+	words = append(words,
+		1, uint32(scene.Page), 0, NoParent, 0, 13,
+		2, uint32(scene.Frame), 0, 0, 13, 44)
+	words = append(words, props...)
+	words[9] = uint32(len(words))
+
+	return words
+}
+
+func TestSnapshot_Migration(t *testing.T) {
+	old := encodeV1()
+
+	root, err := Decode(append([]uint32{}, old...))
+	if err != nil {
+		t.Fatalf("Migration from 1.x failed: %v", err)
+	}
+
+	if root.Style.Opacity != 1 || len(root.Children) != 1 {
+		t.Fatalf("Wrong page after migration, got %+v", root.Style)
+	}
+
+	frame := root.Children[0].Style
+	if frame.Width != layout.Px(100) || frame.Height != layout.Px(50) ||
+		frame.Left != layout.Px(10) || frame.Top != layout.Px(0) {
+		t.Errorf("Lengths should become pixel Dimensions, got %+v", frame)
+	}
+	if frame.Margin != style.UniformEdges(layout.Px(4)) || frame.Padding != style.UniformEdges(layout.Px(8)) {
+		t.Errorf("Box model should apply to every side, got %+v %+v", frame.Margin, frame.Padding)
+	}
+	if frame.Opacity != 0.5 || frame.Layout != layout.ModeFlex ||
+		frame.MinWidth != layout.Pct(50) || frame.AspectRatio != 2 {
+		t.Errorf("Other fields should be kept, got %+v", frame)
+	}
+	if rect, ok := root.Children[0].Props.(*scene.RectProps); !ok || rect.Fill != 0xFF0000FF {
+		t.Errorf("Props should be kept, got %+v", root.Children[0].Props)
+	}
+
+	// Without the migration, 1.x can't be read
+	saved := migrations[1]
+	delete(migrations, 1)
+	_, err = Decode(append([]uint32{}, old...))
+	migrations[1] = saved
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion without migration, got %v", err)
+	}
+
+	// Every truncation of the property region must fail cleanly
+	for size := 23; size < len(old); size++ {
+		truncated := append([]uint32{}, old[:size]...)
+		truncated[9] = uint32(size)
+		if _, err := Decode(truncated); err == nil {
+			t.Fatalf("Truncated 1.x snapshot (%d/%d words) should fail", size, len(old))
+		}
+	}
+
+	words, _ := Encode(randomPage(rand.New(rand.NewSource(2)), 10))
+	future := append([]uint32{}, words...)
+	future[1] = (VersionMajor + 1) << 16
+	if _, err := Decode(future); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion for next major, got %v", err)
+	}
+}
+
+func TestSnapshot_Corrupted(t *testing.T) {
+	words, _ := Encode(randomPage(rand.New(rand.NewSource(3)), 50))
+
+	if _, err := Decode([]uint32{0xDEADBEEF, Version}); !errors.Is(err, ErrInvalidMagic) {
+		t.Errorf("Expected ErrInvalidMagic, got %v", err)
+	}
+
+	// Every truncation must fail cleanly instead of panicking
+	for size := 0; size < len(words); size++ {
+		if _, err := Decode(words[:size]); err == nil {
+			t.Fatalf("Truncated snapshot (%d/%d words) should fail", size, len(words))
+		}
+	}
+
+	// String count larger than the buffer can hold
+	huge := append([]uint32{}, words...)
+	huge[6] = math.MaxUint32
+	if _, err := Decode(huge); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Expected ErrCorrupted for string count, got %v", err)
+	}
+
+	// Point the second record to itself as parent
+	broken := append([]uint32{}, words...)
+	broken[broken[7]+NodeRecordWords+3] = 1
+	if _, err := Decode(broken); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Expected ErrCorrupted for parent cycle, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package style
+
+// Constraint decides how a child of a free layout parent follows
+// the parent on one axis when the parent is resized, like Figma
+type Constraint int
+
+const (
+	// Keeps its distance to the left (top) edge and its size
+	ConstraintStart Constraint = iota
+	// Keeps its distance to the right (bottom) edge and its size
+	ConstraintEnd
+	// Keeps both distances, the size follows the parent
+	ConstraintStartEnd
+	// Keeps its offset from the parent center and its size
+	ConstraintCenter
+	// Position and size scale with the parent
+	ConstraintScale
+)
+
+// Constraints of a node, applied by its parent's resize.
+// The zero value pins the node to the top left corner.
+type Constraints struct {
+	Horizontal Constraint `json:"horizontal"`
+	Vertical   Constraint `json:"vertical"`
+}
+
+// Apply returns the new position and size of a child box on one axis
+// after its parent went from oldLength to newLength
+func (c Constraint) Apply(pos, size, oldLength, newLength float32) (float32, float32) {
+	delta := newLength - oldLength
+
+	switch c {
+	case ConstraintEnd:
+		return pos + delta, size
+	case ConstraintStartEnd:
+		return pos, max(0, size+delta)
+	case ConstraintCenter:
+		return pos + delta/2, size
+	case ConstraintScale:
+		if oldLength <= 0 {
+			return pos, size
+		}
+		k := newLength / oldLength
+		return pos * k, size * k
+	}
+
+	return pos, size
+}
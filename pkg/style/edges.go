@@ -0,0 +1,66 @@
+package style
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"engo/pkg/layout"
+)
+
+// Edges holds one length per side of the box (margin, padding, border)
+type Edges struct {
+	Top    layout.Dimension `json:"top"`
+	Right  layout.Dimension `json:"right"`
+	Bottom layout.Dimension `json:"bottom"`
+	Left   layout.Dimension `json:"left"`
+}
+
+func UniformEdges(d layout.Dimension) Edges {
+	return Edges{Top: d, Right: d, Bottom: d, Left: d}
+}
+
+// IsUniform reports whether all sides hold the same length
+func (e Edges) IsUniform() bool {
+	return e.Top == e.Right && e.Top == e.Bottom && e.Top == e.Left
+}
+
+// Resolve converts the sides to pixel. Like CSS, percentages of every
+// side refer to the width of the containing block.
+func (e Edges) Resolve(parentW float32) layout.Edges {
+	return layout.Edges{
+		Top:    e.Top.Resolve(parentW),
+		Right:  e.Right.Resolve(parentW),
+		Bottom: e.Bottom.Resolve(parentW),
+		Left:   e.Left.Resolve(parentW),
+	}
+}
+
+type edgesJSON Edges
+
+// UnmarshalJSON accepts one Dimension for all sides (8, "8px", "5%")
+// or an object with top, right, bottom and left
+func (e *Edges) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var sides edgesJSON
+		if err := json.Unmarshal(data, &sides); err != nil {
+			return err
+		}
+		*e = Edges(sides)
+		return nil
+	}
+
+	var d layout.Dimension
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	*e = UniformEdges(d)
+	return nil
+}
+
+// MarshalJSON writes a single Dimension when all sides are equal
+func (e Edges) MarshalJSON() ([]byte, error) {
+	if e.IsUniform() {
+		return json.Marshal(e.Top)
+	}
+	return json.Marshal(edgesJSON(e))
+}
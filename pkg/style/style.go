@@ -1,6 +1,9 @@
 package style
 
+import "engo/pkg/layout"
+
 // TODO: Convert all of these into bit fields
+// Same values as layout.Position
 type Position int
 
 const (
@@ -15,25 +18,45 @@ type Overflow int
 const (
 	OverflowVisible Overflow = iota
 	OverflowHidden
+	// Clips like OverflowHidden and children scroll inside,
+	// see scene.Node ScrollX/ScrollY
+	OverflowScroll
 )
 
-// This should hold both numeric, percentage and auto value
-// TODO: should use this
-type Length string
-
 type Style struct {
 	// Dimensions
-	Height, Width float32
+	Width, Height layout.Dimension
+
+	// Size bounds, an auto or zero max means no maximum
+	MinWidth, MaxWidth   layout.Dimension
+	MinHeight, MaxHeight layout.Dimension
+	// Width / height, 0 if the node keeps no ratio
+	AspectRatio float32
 
 	// Positioned
-	Top, Right, Bottom, Left float32
-
-	Margin, Padding float32
-	BorderWidth     float32
-	Position        Position
-	Overflow        Overflow
-	Opacity         float32
-	// TODO: Add transform
+	Top, Right, Bottom, Left layout.Dimension
+
+	// Box model, per side
+	Margin, Padding Edges
+	BorderWidth     Edges
+
+	Position Position
+	Overflow Overflow
+	Opacity  float32
+	// Degrees clockwise around the center of the box
+	Rotation float32
+
+	// How children are placed (free or auto layout)
+	Layout layout.Mode
+	// Container properties when Layout is layout.ModeFlex,
+	// Grow/Shrink apply to the node itself inside a flex parent
+	Flex layout.Flex
+	// Container properties when Layout is layout.ModeGrid
+	Grid layout.Grid
+	// Placement of the node itself inside a grid parent
+	GridItem layout.GridItem
+	// How the node follows a free layout parent that is resized
+	Constraints Constraints
 }
 
 type Align int
@@ -52,6 +75,18 @@ type FontStyle struct {
 	TextAlign  Align
 }
 
+// NewStyle returns the style of a fresh node: auto size and insets,
+// no margin, padding or border, no maximum size and fully opaque
 func NewStyle() *Style {
-	return nil
+	return &Style{
+		Width:     layout.Auto(),
+		Height:    layout.Auto(),
+		MaxWidth:  layout.Auto(),
+		MaxHeight: layout.Auto(),
+		Top:       layout.Auto(),
+		Right:     layout.Auto(),
+		Bottom:    layout.Auto(),
+		Left:      layout.Auto(),
+		Opacity:   1,
+	}
 }
@@ -0,0 +1,138 @@
+// Package text measures and breaks text into lines from font metrics
+// registered by JS. Glyphs are never rasterized here, the browser draws
+// them; Go only needs advances to size text nodes in the layout pass.
+package text
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidFont = errors.New("text: invalid font metrics")
+
+// Words of the metrics header, see ParseFont
+const metricsHeaderWords = 8
+
+// Font holds the metrics of one font face, in font units
+type Font struct {
+	Family     string
+	UnitsPerEm float32
+
+	// Above and below the baseline, both positive
+	Ascent, Descent float32
+	LineGap         float32
+
+	// Advance of runes missing from Advances
+	DefaultAdvance float32
+	Advances       map[rune]float32
+	// Adjustment between two runes, added to the advance of the first
+	Kerning map[[2]rune]float32
+}
+
+// Fallback sizes text when no font is registered yet, an average
+// sans-serif so layout stays close until the real metrics arrive
+var Fallback = &Font{
+	UnitsPerEm:     1000,
+	Ascent:         800,
+	Descent:        200,
+	DefaultAdvance: 500,
+}
+
+// ParseFont reads the metrics JS extracted from a font file:
+//
+//	[0] units per em
+//	[1] ascent           float32 bits
+//	[2] descent          float32 bits
+//	[3] line gap         float32 bits
+//	[4] default advance  float32 bits
+//	[5] glyph count G
+//	[6] kerning pair count K
+//	[7] family byte length L
+//	family UTF-8 bytes, padded to a word boundary
+//	G x [codepoint, advance]
+//	K x [left codepoint, right codepoint, adjustment]
+func ParseFont(words []uint32) (*Font, error) {
+	if len(words) < metricsHeaderWords {
+		return nil, fmt.Errorf("%w: header", ErrInvalidFont)
+	}
+
+	glyphs, pairs, nameLen := int(words[5]), int(words[6]), int(words[7])
+	nameWords := (nameLen + 3) / 4
+	need := uint64(metricsHeaderWords) + uint64(nameWords) + 2*uint64(glyphs) + 3*uint64(pairs)
+	if words[0] == 0 || need > uint64(len(words)) {
+		return nil, fmt.Errorf("%w: %d words, need %d", ErrInvalidFont, len(words), need)
+	}
+
+	f := &Font{
+		UnitsPerEm:     float32(words[0]),
+		Ascent:         math.Float32frombits(words[1]),
+		Descent:        math.Float32frombits(words[2]),
+		LineGap:        math.Float32frombits(words[3]),
+		DefaultAdvance: math.Float32frombits(words[4]),
+		Advances:       make(map[rune]float32, glyphs),
+		Kerning:        make(map[[2]rune]float32, pairs),
+	}
+
+	i := metricsHeaderWords
+	name := make([]byte, nameLen)
+	for j := range name {
+		name[j] = byte(words[i+j/4] >> (8 * (j % 4)))
+	}
+	f.Family = string(name)
+	i += nameWords
+
+	for range glyphs {
+		f.Advances[rune(words[i])] = math.Float32frombits(words[i+1])
+		i += 2
+	}
+	for range pairs {
+		f.Kerning[[2]rune{rune(words[i]), rune(words[i+1])}] = math.Float32frombits(words[i+2])
+		i += 3
+	}
+
+	return f, nil
+}
+
+func (f *Font) advance(r rune) float32 {
+	if a, ok := f.Advances[r]; ok {
+		return a
+	}
+	return f.DefaultAdvance
+}
+
+// scale converts font units to pixels at size
+func (f *Font) scale(size float32) float32 {
+	return size / f.UnitsPerEm
+}
+
+// Registry keeps registered fonts by ID and by family
+type Registry struct {
+	byID     map[uint32]*Font
+	byFamily map[string]*Font
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		byID:     make(map[uint32]*Font),
+		byFamily: make(map[string]*Font),
+	}
+}
+
+// Register adds or replaces font id, the latest font of a family wins
+func (r *Registry) Register(id uint32, f *Font) {
+	if old, ok := r.byID[id]; ok && r.byFamily[old.Family] == old {
+		delete(r.byFamily, old.Family)
+	}
+
+	r.byID[id] = f
+	r.byFamily[f.Family] = f
+}
+
+// Lookup returns the font of family, Fallback if none is registered
+func (r *Registry) Lookup(family string) *Font {
+	if f, ok := r.byFamily[family]; ok {
+		return f
+	}
+	return Fallback
+}
@@ -0,0 +1,166 @@
+package text
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Style is how a run of text is set, sizes in pixel
+type Style struct {
+	Size float32
+	// Multiple of Size, 0 uses the font's own ascent + descent + line gap
+	LineHeight float32
+	// Added after every rune
+	LetterSpacing float32
+}
+
+// Line is one laid out line, Start/End are byte offsets into the content.
+// Trailing spaces belong to the line but not to its Width.
+type Line struct {
+	Start, End int
+	Width      float32
+}
+
+// Block is laid out text, lines are LineHeight apart
+type Block struct {
+	Lines      []Line
+	LineHeight float32
+	// Baseline of the first line from the top
+	Baseline      float32
+	Width, Height float32
+}
+
+// Measure returns the size of content broken at maxWidth
+func (f *Font) Measure(content string, s Style, maxWidth float32) (float32, float32) {
+	b := f.Layout(content, s, maxWidth)
+	return b.Width, b.Height
+}
+
+// Layout breaks content into lines no wider than maxWidth, at spaces
+// first, inside a word only when the word alone doesn't fit. "\n" always
+// starts a new line. maxWidth <= 0 means no limit.
+func (f *Font) Layout(content string, s Style, maxWidth float32) Block {
+	scale := f.scale(s.Size)
+
+	b := Block{
+		LineHeight: (f.Ascent + f.Descent + f.LineGap) * scale,
+		Baseline:   f.Ascent * scale,
+	}
+	if s.LineHeight > 0 {
+		// Extra leading is split above and below, like CSS
+		b.LineHeight = s.LineHeight * s.Size
+		b.Baseline += (b.LineHeight - (f.Ascent+f.Descent)*scale) / 2
+	}
+
+	br := breaker{font: f, style: s, scale: scale, maxWidth: maxWidth}
+	start := 0
+	for {
+		end := strings.IndexByte(content[start:], '\n')
+		if end < 0 {
+			br.paragraph(content, start, len(content))
+			break
+		}
+		br.paragraph(content, start, start+end)
+		start += end + 1
+	}
+
+	b.Lines = br.lines
+	for _, line := range b.Lines {
+		b.Width = max(b.Width, line.Width)
+	}
+	b.Height = b.LineHeight * float32(len(b.Lines))
+	return b
+}
+
+type breaker struct {
+	font     *Font
+	style    Style
+	scale    float32
+	maxWidth float32
+
+	lines []Line
+}
+
+// width of content[start:end] on one line
+func (br *breaker) width(content string, start, end int) float32 {
+	var w float32
+	prev := rune(-1)
+	for _, r := range content[start:end] {
+		if prev >= 0 {
+			w += br.font.Kerning[[2]rune{prev, r}] * br.scale
+		}
+		w += br.font.advance(r)*br.scale + br.style.LetterSpacing
+		prev = r
+	}
+	return w
+}
+
+func (br *breaker) fits(w float32) bool {
+	return br.maxWidth <= 0 || w <= br.maxWidth
+}
+
+// paragraph greedily fills lines with the words of content[start:end]
+func (br *breaker) paragraph(content string, start, end int) {
+	line := Line{Start: start, End: start}
+	lineEmpty := true
+
+	i := start
+	for i < end {
+		// Spaces before the next word, then the word
+		wordStart := i
+		for wordStart < end && content[wordStart] == ' ' {
+			wordStart++
+		}
+		wordEnd := wordStart
+		for wordEnd < end && content[wordEnd] != ' ' {
+			wordEnd++
+		}
+
+		spaces := br.width(content, i, wordStart)
+		word := br.width(content, wordStart, wordEnd)
+
+		switch {
+		case wordEnd == wordStart:
+			// Trailing spaces hang, they never wrap
+			line.End = wordEnd
+		case lineEmpty && !br.fits(word):
+			// Leading spaces stay on the line, the word is cut where it overflows
+			line.Width += spaces
+			line.End = wordStart
+			line = br.breakWord(content, line, wordStart, wordEnd)
+		case lineEmpty || br.fits(line.Width+spaces+word):
+			line.Width += spaces + word
+			line.End = wordEnd
+		default:
+			// Spaces hang at the end of the full line
+			line.End = wordStart
+			br.lines = append(br.lines, line)
+			line = Line{Start: wordStart, End: wordEnd, Width: word}
+			if !br.fits(word) {
+				line = br.breakWord(content, Line{Start: wordStart, End: wordStart}, wordStart, wordEnd)
+			}
+		}
+
+		lineEmpty = line.End == line.Start
+		i = wordEnd
+	}
+
+	br.lines = append(br.lines, line)
+}
+
+// breakWord appends content[start:end] rune by rune to line, flushing
+// full lines, and returns the last, unfinished line
+func (br *breaker) breakWord(content string, line Line, start, end int) Line {
+	for i := start; i < end; {
+		_, size := utf8.DecodeRuneInString(content[i:])
+		w := br.width(content, i, i+size)
+		if line.End > line.Start && !br.fits(line.Width+w) {
+			br.lines = append(br.lines, line)
+			line = Line{Start: i, End: i}
+		}
+		line.Width += w
+		line.End = i + size
+		i += size
+	}
+	return line
+}
@@ -0,0 +1,150 @@
+package text
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// testFont has 10px runes ('i' is 5px) and 20px lines at size 20
+func testFont() *Font {
+	return &Font{
+		Family:         "Mono",
+		UnitsPerEm:     1000,
+		Ascent:         800,
+		Descent:        200,
+		DefaultAdvance: 500,
+		Advances:       map[rune]float32{'i': 250},
+		Kerning:        map[[2]rune]float32{{'A', 'V'}: -100},
+	}
+}
+
+func TestLayout_Wrap(t *testing.T) {
+	f := testFont()
+	s := Style{Size: 20}
+
+	cases := []struct {
+		name     string
+		content  string
+		maxWidth float32
+		lines    []Line
+	}{
+		{"no limit", "hello world", 0, []Line{{0, 11, 110}}},
+		{"at space", "hello world", 60, []Line{{0, 6, 50}, {6, 11, 50}}},
+		{"trailing spaces hang", "hello   ", 60, []Line{{0, 8, 50}}},
+		{"two words per line", "ab cd ef gh", 50, []Line{{0, 6, 50}, {6, 11, 50}}},
+		{"long word", "abcdefghij", 35, []Line{{0, 3, 30}, {3, 6, 30}, {6, 10, 35}}},
+		{"long word after a word", "ab abcdefgh", 50, []Line{{0, 3, 20}, {3, 8, 50}, {8, 11, 30}}},
+		{"hard break", "a\n\nbc", 0, []Line{{0, 1, 10}, {2, 2, 0}, {3, 5, 20}}},
+		{"advances", "iii", 0, []Line{{0, 3, 15}}},
+		{"empty", "", 100, []Line{{0, 0, 0}}},
+	}
+
+	for _, c := range cases {
+		b := f.Layout(c.content, s, c.maxWidth)
+		if len(b.Lines) != len(c.lines) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.lines, b.Lines)
+			continue
+		}
+		for i, line := range b.Lines {
+			if line != c.lines[i] {
+				t.Errorf("%s: line %d expected %v, got %v", c.name, i, c.lines[i], line)
+			}
+		}
+		if b.Height != 20*float32(len(c.lines)) {
+			t.Errorf("%s: wrong height %v", c.name, b.Height)
+		}
+	}
+}
+
+func TestLayout_Spacing(t *testing.T) {
+	f := testFont()
+
+	if w, _ := f.Measure("ab", Style{Size: 20, LetterSpacing: 2}, 0); w != 24 {
+		t.Errorf("Letter spacing goes after every rune, got %v", w)
+	}
+	if w, _ := f.Measure("AV", Style{Size: 20}, 0); w != 18 {
+		t.Errorf("Kerning should tighten AV, got %v", w)
+	}
+
+	b := f.Layout("a\nb", Style{Size: 20, LineHeight: 1.5}, 0)
+	if b.LineHeight != 30 || b.Height != 60 {
+		t.Errorf("Line height is a multiple of the size, got %v %v", b.LineHeight, b.Height)
+	}
+	// 16px ascent plus half of the 10px extra leading
+	if b.Baseline != 21 {
+		t.Errorf("Wrong baseline, got %v", b.Baseline)
+	}
+}
+
+// encodeFont writes f in the format of ParseFont
+func encodeFont(f *Font) []uint32 {
+	words := []uint32{
+		uint32(f.UnitsPerEm),
+		math.Float32bits(f.Ascent),
+		math.Float32bits(f.Descent),
+		math.Float32bits(f.LineGap),
+		math.Float32bits(f.DefaultAdvance),
+		uint32(len(f.Advances)),
+		uint32(len(f.Kerning)),
+		uint32(len(f.Family)),
+	}
+	for i := 0; i < len(f.Family); i += 4 {
+		var word uint32
+		for j := 0; j < 4 && i+j < len(f.Family); j++ {
+			word |= uint32(f.Family[i+j]) << (8 * j)
+		}
+		words = append(words, word)
+	}
+	for r, a := range f.Advances {
+		words = append(words, uint32(r), math.Float32bits(a))
+	}
+	for pair, k := range f.Kerning {
+		words = append(words, uint32(pair[0]), uint32(pair[1]), math.Float32bits(k))
+	}
+	return words
+}
+
+func TestParseFont(t *testing.T) {
+	want := testFont()
+	want.Family = "Inter Display"
+	words := encodeFont(want)
+
+	f, err := ParseFont(words)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.Family != want.Family || f.UnitsPerEm != 1000 || f.Ascent != 800 || f.DefaultAdvance != 500 {
+		t.Errorf("Wrong metrics, got %+v", f)
+	}
+	if f.advance('i') != 250 || f.Kerning[[2]rune{'A', 'V'}] != -100 {
+		t.Errorf("Wrong glyph tables, got %v %v", f.Advances, f.Kerning)
+	}
+
+	for _, n := range []int{0, 7, len(words) - 1} {
+		if _, err := ParseFont(words[:n]); !errors.Is(err, ErrInvalidFont) {
+			t.Errorf("Truncated to %d words should fail, got %v", n, err)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if r.Lookup("Mono") != Fallback {
+		t.Errorf("Unknown family should use the fallback")
+	}
+
+	mono := testFont()
+	r.Register(1, mono)
+	if r.Lookup("Mono") != mono {
+		t.Errorf("Registered font not found")
+	}
+
+	// Registering id 1 again replaces the old family
+	other := testFont()
+	other.Family = "Serif"
+	r.Register(1, other)
+	if r.Lookup("Mono") != Fallback || r.Lookup("Serif") != other {
+		t.Errorf("Font 1 should now be Serif only")
+	}
+}